@@ -0,0 +1,174 @@
+package prowjob
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/redhat-appstudio/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	aggregateReportDefaultArtifactDir = "./tmp/aggregate-report"
+	scanFailedResult                  = "SCAN FAILED"
+)
+
+// aggregatedJob represents a single Prow job included in the aggregate report
+type aggregatedJob struct {
+	name   string
+	id     string
+	url    string
+	result string
+	suites *reporters.JUnitTestSuites
+	err    error
+}
+
+// aggregateReportCmd represents the aggregate-report command
+var aggregateReportCmd = &cobra.Command{
+	Use:   "aggregate-report [prow-job-id...]",
+	Short: "Analyze multiple prow jobs and create a combined report in junit/html format",
+	Long: `This command analyzes artifacts of the specified prow jobs and creates a combined report in junit/html format.
+Jobs are specified either by their IDs (as arguments), or by their name and the number of the latest runs (--job-name and --last).
+Test suites of each job are prefixed with the job name and build ID, the HTML report starts with a summary table of all jobs.
+The ci-operator targets of the jobs are determined via rules in the create-report config (` + createReportDefaultConfigPath + ` by default)`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		bindSharedFlags(cmd, types.ArtifactDirParamName, jobNameParamName, lastParamName)
+		bindSharedFlags(cmd, reportParamNames...)
+		if len(args) == 0 && viper.GetString(jobNameParamName) == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("neither prow job IDs nor parameter %q provided", jobNameParamName)
+		}
+		if len(args) > 0 && viper.GetString(jobNameParamName) != "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("prow job IDs and parameter %q are mutually exclusive", jobNameParamName)
+		}
+		return readCreateReportConfig()
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := newReportScannerConfig()
+		if err != nil {
+			return err
+		}
+
+		var jobs []aggregatedJob
+		if name := viper.GetString(jobNameParamName); name != "" {
+			jobRuns, err := listJobRuns(cmd.Context(), name, viper.GetInt(lastParamName))
+			if err != nil {
+				return err
+			}
+			for _, jobRun := range jobRuns {
+				jobs = append(jobs, aggregatedJob{name: jobRun.JobName, id: jobRun.ID, url: jobRun.URL})
+			}
+		} else {
+			for _, id := range args {
+				jobs = append(jobs, aggregatedJob{id: id})
+			}
+		}
+
+		for i := range jobs {
+			job := &jobs[i]
+			jobCfg := cfg
+			if job.url != "" {
+				jobCfg.ProwJobURL = job.url
+			} else {
+				jobCfg.ProwJobID = job.id
+			}
+
+			klog.Infof("creating report of the prow job %s (%d/%d)", job.id, i+1, len(jobs))
+			report, err := createJobReport(cmd.Context(), jobCfg, newReportOptions())
+			if err != nil {
+				klog.Errorf("failed to create report for prow job %s: %+v", job.id, err)
+				job.result, job.err = scanFailedResult, err
+				continue
+			}
+			job.name, job.url, job.result, job.suites = report.jobRun.JobName, report.jobRun.URL, report.jobRun.Result, report.suites
+			if report.jobRun.ID != "" {
+				job.id = report.jobRun.ID
+			}
+		}
+
+		artifactDir := viper.GetString(types.ArtifactDirParamName)
+		if artifactDir == "" {
+			artifactDir = aggregateReportDefaultArtifactDir
+			klog.Warningf("path to artifact dir was not provided - using default %q\n", artifactDir)
+		}
+
+		aggregatedSuites := aggregateJUnitSuites(jobs)
+		failureGroups := addFailureGroups(aggregatedSuites)
+		return writeJUnitReport(aggregatedSuites, artifactDir, aggregateSummaryHTML(jobs), failureGroups)
+	},
+}
+
+// aggregateJUnitSuites combines JUnit suites of the given jobs into a single report,
+// prefixing names of the suites with the name and the build ID of the job they belong to
+func aggregateJUnitSuites(jobs []aggregatedJob) *reporters.JUnitTestSuites {
+	aggregated := &reporters.JUnitTestSuites{}
+	for _, job := range jobs {
+		if job.suites == nil {
+			continue
+		}
+		for _, suite := range job.suites.TestSuites {
+			suite.Name = fmt.Sprintf("[%s #%s] %s", job.name, job.id, suite.Name)
+			suite.Properties.Properties = append(suite.Properties.Properties,
+				reporters.JUnitProperty{Name: "prow-job-name", Value: job.name},
+				reporters.JUnitProperty{Name: "prow-job-id", Value: job.id},
+				reporters.JUnitProperty{Name: "prow-job-url", Value: job.url})
+			aggregated.TestSuites = append(aggregated.TestSuites, suite)
+		}
+		aggregated.Tests += job.suites.Tests
+		aggregated.Failures += job.suites.Failures
+		aggregated.Errors += job.suites.Errors
+		aggregated.Disabled += job.suites.Disabled
+		aggregated.Time += job.suites.Time
+	}
+	return aggregated
+}
+
+// aggregateSummaryHTML returns an HTML table summarizing results of the given jobs
+func aggregateSummaryHTML(jobs []aggregatedJob) string {
+	var sb strings.Builder
+	var passedJobs, tests, failures, errors, skipped int
+
+	writeHTMLTableStart(&sb, "Summary of prow jobs", "Job", "Build ID", "Result", "Tests", "Failures", "Errors", "Skipped")
+	for _, job := range jobs {
+		build := html.EscapeString(job.id)
+		if job.url != "" {
+			build = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(job.url), build)
+		}
+		result := html.EscapeString(job.result)
+		if job.err != nil {
+			result = fmt.Sprintf(`<span title="%s">%s</span>`, html.EscapeString(job.err.Error()), result)
+		}
+		if job.result == "SUCCESS" {
+			passedJobs++
+		}
+
+		var jobTests, jobFailures, jobErrors, jobSkipped int
+		if job.suites != nil {
+			jobTests, jobFailures, jobErrors = job.suites.Tests, job.suites.Failures, job.suites.Errors
+			for _, suite := range job.suites.TestSuites {
+				jobSkipped += suite.Skipped + suite.Disabled
+			}
+		}
+		tests, failures, errors, skipped = tests+jobTests, failures+jobFailures, errors+jobErrors, skipped+jobSkipped
+
+		fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>",
+			html.EscapeString(job.name), build, result, jobTests, jobFailures, jobErrors, jobSkipped)
+	}
+	fmt.Fprintf(&sb, "<tr><th>Total</th><th>%d jobs</th><th>%d/%d passed</th><th>%d</th><th>%d</th><th>%d</th><th>%d</th></tr></table>",
+		len(jobs), passedJobs, len(jobs), tests, failures, errors, skipped)
+
+	return sb.String()
+}
+
+func init() {
+	aggregateReportCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store produced files (defaults to "+aggregateReportDefaultArtifactDir+")")
+	aggregateReportCmd.Flags().StringVar(&jobName, jobNameParamName, "", "Name of the prow job whose latest runs should be analyzed (alternative to job IDs)")
+	aggregateReportCmd.Flags().IntVar(&last, lastParamName, 5, "Number of the latest runs of the --"+jobNameParamName+" job to analyze")
+	addReportFlags(aggregateReportCmd)
+}
@@ -1,28 +1,21 @@
 package prowjob
 
 import (
-	"bufio"
-	"encoding/xml"
 	"fmt"
-	"io"
+	"html"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
+	"github.com/redhat-appstudio/qe-tools/pkg/classification"
 	"github.com/redhat-appstudio/qe-tools/pkg/customjunit"
+	"github.com/redhat-appstudio/qe-tools/pkg/knownissues"
 	"github.com/redhat-appstudio/qe-tools/pkg/types"
 
-	"github.com/GoogleCloudPlatform/testgrid/metadata"
 	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+	"github.com/redhat-appstudio/qe-tools/pkg/timeline"
 
 	"k8s.io/klog/v2"
-	"sigs.k8s.io/yaml"
-
-	reporters "github.com/onsi/ginkgo/v2/reporters"
-	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
-
-	"github.com/redhat-appstudio-qe/junit2html/pkg/convert"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -31,212 +24,201 @@ import (
 var (
 	formatReportPortal bool
 	stepsToSkip        []string
+	concurrency        int
+	objectTimeout      time.Duration
+	spoolDir           string
+	maxInMemorySize    int64
+	cacheDir           string
+	cacheMaxSize       int64
+	layout             string
 )
 
 const (
 	buildLogFilename = "build-log.txt"
 	finishedFilename = "finished.json"
+	startedFilename  = "started.json"
+	timelineFilename = "timeline.json"
+
+	reportPortalFormatParamName   = "report-portal-format"
+	stepsToSkipParamName          = "skip-ci-steps"
+	concurrencyParamName          = "concurrency"
+	objectTimeoutParamName        = "object-timeout"
+	spoolDirParamName             = "spool-dir"
+	maxInMemorySizeParamName      = "max-in-memory-size"
+	cacheDirParamName             = "cache-dir"
+	cacheMaxSizeParamName         = "cache-max-size"
+	layoutParamName               = "layout"
+	slowStepThresholdParamName    = "slow-step-threshold"
+	fromDirParamName              = "from-dir"
+	knownIssuesParamName          = "known-issues"
+	downgradeKnownIssuesParamName = "downgrade-known-issues"
+	openshiftCITestSuiteName      = "openshift-ci job"
+
+	createReportDefaultConfigPath  = "./config/create-report/config.yaml"
+	createReportCmdLongDescription = `This command analyzes artifacts of the specified prow job and creates a report in junit/html format.
+The ci-operator target of the job is determined via rules in the config, followed by the built-in rules. The default config is located in ` + createReportDefaultConfigPath + `,
+however user can provide their own config via --config=<path-to-config> option.
+Artifacts of jobs that were not run by ci-operator are scanned using the generic layout (see --layout).
+The report can also be created from artifacts of a job stored in a local directory (see --from-dir).
+Failed steps and tests are classified (cluster provisioning, external-service outage, test failure or unknown)
+using the rules from the config (under the "failureCategories" key) and the default ones.
+Failures matching known issue rules (see --known-issues) are annotated with links to the issues.
+The report can be uploaded to Report Portal as a new launch (see --report-portal-url).
+Besides the junit/html report, a Markdown summary and a timeline of the job's steps (timeline.json) and a machine-readable report (report.json) are stored in the artifact dir
+`
+)
 
-	gcsBrowserURLPrefix = "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/"
+var createReportConfig CreateReportConfig
 
-	reportPortalFormatParamName = "report-portal-format"
-	stepsToSkipParamName        = "skip-ci-steps"
-	openshiftCITestSuiteName    = "openshift-ci job"
-)
+// CreateReportConfig represents configuration of the create-report command
+type CreateReportConfig struct {
+	JobTargets []prow.JobTargetRule `json:"jobTargets"`
+	// KnownIssues are rules annotating failures with links to known issues
+	KnownIssues []knownissues.Rule `json:"knownIssues"`
+	// FailureCategories are rules classifying failures, applied before the default ones
+	FailureCategories []classification.Rule `json:"failureCategories"`
+}
 
 // createReportCmd represents the createReport command
 var createReportCmd = &cobra.Command{
 	Use:   "create-report",
 	Short: "Analyze specified prow job and create a report in junit/html format",
+	Long:  createReportCmdLongDescription,
 	PreRunE: func(cmd *cobra.Command, _ []string) error {
-		if viper.GetString(types.ProwJobIDParamName) == "" {
+		bindSharedFlags(cmd, types.ProwJobIDParamName, types.ArtifactDirParamName)
+		bindSharedFlags(cmd, reportParamNames...)
+		if viper.GetString(fromDirParamName) != "" {
+			if cmd.Flags().Changed(types.ProwJobIDParamName) {
+				_ = cmd.Usage()
+				return fmt.Errorf("parameters %q and %q are mutually exclusive", types.ProwJobIDParamName, fromDirParamName)
+			}
+		} else if viper.GetString(types.ProwJobIDParamName) == "" {
 			_ = cmd.Usage()
 			return fmt.Errorf("parameter %q not provided, neither %s env var was set", types.ProwJobIDParamName, types.ProwJobIDEnv)
 		}
-		return nil
+		return readCreateReportConfig()
 	},
 	SilenceUsage: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		prowJobID = viper.GetString(types.ProwJobIDParamName)
-		stepsToSkip = viper.GetStringSlice(stepsToSkipParamName)
-
-		cfg := prow.ScannerConfig{
-			ProwJobID:      prowJobID,
-			FileNameFilter: []string{finishedFilename, buildLogFilename, types.JunitFilename},
-			StepsToSkip:    stepsToSkip,
-		}
 
-		scanner, err := prow.NewArtifactScanner(cfg)
+		cfg, err := newReportScannerConfig()
 		if err != nil {
-			return fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+			return err
 		}
-
-		if err := scanner.Run(); err != nil {
-			return fmt.Errorf("failed to scan artifacts for prow job %s: %+v", prowJobID, err)
+		job := "prow job " + prowJobID
+		if dir := viper.GetString(fromDirParamName); dir != "" {
+			jobDirectoryPrefix, err := prow.LocalJobDirectoryPrefix(dir, cfg.Instance)
+			if err != nil {
+				return err
+			}
+			cfg.ArtifactSource = prow.NewLocalJobSource(dir, jobDirectoryPrefix)
+			cfg.ProwJobURL = cfg.Instance.ProwJobURL(jobDirectoryPrefix)
+			job = "directory " + dir
+		} else {
+			cfg.ProwJobID = prowJobID
 		}
 
-		overallJUnitSuites := &reporters.JUnitTestSuites{}
-		openshiftCiJunit := reporters.JUnitTestSuite{Name: openshiftCITestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
-
-		htmlReportLink := gcsBrowserURLPrefix + scanner.ArtifactDirectoryPrefix + "redhat-appstudio-report/artifacts/junit-summary.html"
-		openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: "html-report-link", Value: htmlReportLink})
-
-		for stepName, artifactsFilenameMap := range scanner.ArtifactStepMap {
-			for artifactFilename, artifact := range artifactsFilenameMap {
-				if artifactFilename == finishedFilename {
-					if strings.Contains(string(stepName), "gather") {
-						openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: string(stepName), Value: gcsBrowserURLPrefix + strings.TrimSuffix(artifact.FullName, finishedFilename) + "artifacts"})
-					}
-
-					finished := metadata.Finished{}
-					err = yaml.Unmarshal([]byte(artifact.Content), &finished)
-					if err != nil {
-						return fmt.Errorf("cannot unmarshal %s into finished struct: %+v", artifact.Content, err)
-					}
-
-					var buildLog string
-					if val, ok := artifactsFilenameMap[buildLogFilename]; ok {
-						buildLog = val.Content
-					}
-
-					if *finished.Passed {
-						openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStatePassed.String(), SystemErr: buildLog})
-					} else {
-						failure := &reporters.JUnitFailure{Message: fmt.Sprintf("%s has failed", stepName)}
-						tc := reporters.JUnitTestCase{Name: string(stepName), Status: ginkgoTypes.SpecStateFailed.String(), Failure: failure, SystemErr: buildLog}
-						openshiftCiJunit.Failures++
-						openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, tc)
-					}
-					openshiftCiJunit.Tests++
-				} else if strings.Contains(string(artifactFilename), ".xml") {
-					if err = xml.Unmarshal([]byte(artifact.Content), overallJUnitSuites); err != nil {
-						klog.Errorf("cannot decode JUnit suite %q into xml: %+v", artifactFilename, err)
-					}
-				}
-			}
+		report, err := createJobReport(cmd.Context(), cfg, newReportOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create report for %s: %+v", job, err)
 		}
+		overallJUnitSuites := report.suites
 
 		artifactDir := viper.GetString(types.ArtifactDirParamName)
 		if artifactDir == "" {
-			artifactDir = "./tmp/" + prowJobID
+			artifactDir = "./tmp/" + report.jobRun.ID
 			klog.Warningf("path to artifact dir was not provided - using default %q\n", artifactDir)
 		}
 
-		if err := os.MkdirAll(artifactDir, 0o750); err != nil {
-			return fmt.Errorf("failed to create directory for results '%s': %+v", artifactDir, err)
-		}
-
-		// Add timestamp to openshift-ci job
-		if len(overallJUnitSuites.TestSuites) > 0 {
-			openshiftCiJunit.Timestamp = overallJUnitSuites.TestSuites[0].Timestamp
-		} else {
-			openshiftCiJunit.Timestamp = time.Now().Format("2006-01-02T15:04:05")
-		}
-
-		overallJUnitSuites.TestSuites = append(overallJUnitSuites.TestSuites, openshiftCiJunit)
-		overallJUnitSuites.Failures += openshiftCiJunit.Failures
-		overallJUnitSuites.Errors += openshiftCiJunit.Errors
-		overallJUnitSuites.Tests += openshiftCiJunit.Tests
-
-		// Omit system-err from passed test cases
-		for i := range overallJUnitSuites.TestSuites {
-			for j := range overallJUnitSuites.TestSuites[i].TestCases {
-				tc := &overallJUnitSuites.TestSuites[i].TestCases[j]
-				if tc.Status == "passed" {
-					tc.SystemErr = ""
-				}
-			}
+		knownIssueMatches, knownIssues, err := applyKnownIssues(overallJUnitSuites)
+		if err != nil {
+			return err
 		}
-
-		generatedJunitFilepath := filepath.Clean(artifactDir + "/junit.xml")
-		outFile, err := os.Create(generatedJunitFilepath)
+		failureClassification, err := classifyFailures(overallJUnitSuites)
 		if err != nil {
-			return fmt.Errorf("cannot create file '%s': %+v", generatedJunitFilepath, err)
+			return err
 		}
-
-		if err := xml.NewEncoder(bufio.NewWriter(outFile)).Encode(overallJUnitSuites); err != nil {
-			return fmt.Errorf("cannot encode JUnit suites struct '%+v' into file located at '%s': %+v", overallJUnitSuites, generatedJunitFilepath, err)
+		classificationHTML := fmt.Sprintf("<p><b>Failure classification:</b> %s</p>", html.EscapeString(failureClassification))
+		failureGroups := addFailureGroups(overallJUnitSuites)
+		jobTimeline := timeline.New(report.jobRun, timeline.Options{SlowThreshold: viper.GetDuration(slowStepThresholdParamName)})
+		if err := writeJUnitReport(overallJUnitSuites, artifactDir, classificationHTML, knownIssues, failureGroups, jobTimeline.HTML()); err != nil {
+			return err
 		}
-
-		html, err := convert.Convert(overallJUnitSuites)
+		if err := writeJSONFile(filepath.Join(artifactDir, timelineFilename), jobTimeline); err != nil {
+			return err
+		}
+		jsonReport, err := newJSONReport(report)
 		if err != nil {
-			return fmt.Errorf("failed to convert junit suite to html: %+v", err)
+			return err
 		}
-		if err := os.WriteFile(artifactDir+"/junit-summary.html", []byte(html), 0o600); err != nil {
-			return fmt.Errorf("failed to create HTML file with test summary: %+v", err)
+		if err := writeJSONFile(filepath.Join(artifactDir, jsonReportFilename), jsonReport); err != nil {
+			return err
+		}
+		if err := writeMarkdownReport(report, artifactDir, knownIssueMatches, failureClassification); err != nil {
+			return err
 		}
-
-		klog.Infof("JUnit report saved to: %s/junit.xml", artifactDir)
-		klog.Infof("HTML report saved to: %s/junit-summary.html", artifactDir)
 
 		if formatReportPortal {
-			reportPortalSuites := &customjunit.TestSuites{}
-			if err := readXMLFile(fmt.Sprintf("%s/junit.xml", artifactDir), reportPortalSuites); err != nil {
-				return fmt.Errorf("could not read junit.xml file")
-			}
-
-			changeDisabledToSkipped(overallJUnitSuites, reportPortalSuites)
-
-			generatedReportPortalFilepath := filepath.Clean(artifactDir + "/junit-rp.xml")
-			outRPFile, err := os.Create(generatedReportPortalFilepath)
-			if err != nil {
-				return fmt.Errorf("cannot create file '%s': %+v", generatedReportPortalFilepath, err)
+			if err := writeXMLFile(filepath.Join(artifactDir, "junit-rp.xml"), customjunit.FromGinkgo(overallJUnitSuites)); err != nil {
+				return err
 			}
+			klog.Infof("JUnit report for Report Portal saved to: %s/junit-rp.xml", artifactDir)
+		}
 
-			if err := xml.NewEncoder(bufio.NewWriter(outRPFile)).Encode(reportPortalSuites); err != nil {
-				return fmt.Errorf("cannot encode JUnit suites struct '%+v' into file located at '%s': %+v", reportPortalSuites, generatedJunitFilepath, err)
+		if viper.GetString(reportPortalURLParamName) != "" {
+			if err := uploadToReportPortal(cmd.Context(), jsonReport, overallJUnitSuites); err != nil {
+				return err
 			}
-			klog.Infof("JUnit report for Report Portal saved to: %s/junit-rp.xml", artifactDir)
 		}
 
 		return nil
 	},
 }
 
-func readXMLFile(xmlPath string, result any) error {
-	xmlFile, err := os.Open(filepath.Clean(xmlPath))
-	if err != nil {
-		return fmt.Errorf("could not open file '%s', error: %v", xmlPath, err)
+// readCreateReportConfig reads the config provided via --config option, or the default config if it exists
+func readCreateReportConfig() error {
+	if viper.ConfigFileUsed() == "" {
+		if _, err := os.Stat(createReportDefaultConfigPath); err != nil {
+			klog.Infof("default config %q not found - using the built-in job target rules", createReportDefaultConfigPath)
+			return nil
+		}
+		viper.SetConfigFile(createReportDefaultConfigPath)
 	}
-	defer xmlFile.Close()
-
-	xmlBytes, err := io.ReadAll(xmlFile)
-	if err != nil {
-		return err
+	if err := viper.ReadInConfig(); err != nil {
+		return fmt.Errorf("err readinconfig: %+v", err)
 	}
-
-	if err = xml.Unmarshal(xmlBytes, &result); err != nil {
-		klog.Errorf("cannot decode JUnit suite %q into xml: %+v", xmlPath, err)
+	if err := viper.Unmarshal(&createReportConfig); err != nil {
+		return fmt.Errorf("failed to parse config: %+v", err)
 	}
-
 	return nil
 }
 
-func changeDisabledToSkipped(original *reporters.JUnitTestSuites, custom *customjunit.TestSuites) {
-	totalSkipped := 0
-	for _, suite := range original.TestSuites {
-		if suite.Disabled != 0 {
-			for i := range custom.TestSuites {
-				if custom.TestSuites[i].Name == suite.Name {
-					custom.TestSuites[i].Skipped += suite.Disabled
-				}
-				totalSkipped += custom.TestSuites[i].Skipped
-			}
-		}
-	}
-	custom.Skipped = totalSkipped
-}
-
 func init() {
 	createReportCmd.Flags().StringVar(&prowJobID, types.ProwJobIDParamName, "", "Prow job ID to analyze")
 	createReportCmd.Flags().BoolVar(&formatReportPortal, reportPortalFormatParamName, false, "Format for Report Portal")
-	createReportCmd.Flags().StringArrayVar(&stepsToSkip, stepsToSkipParamName, []string{"redhat-appstudio-report"}, "List of CI steps to skip when gathering artifacts")
+	createReportCmd.Flags().String(reportPortalURLParamName, "", "URL of the Report Portal instance to upload the report to as a new launch (the report is not uploaded if empty)")
+	createReportCmd.Flags().String(reportPortalProjectParamName, "", "Name of the Report Portal project to upload the report to")
+	createReportCmd.Flags().String(reportPortalLaunchNameParamName, "", "Name of the Report Portal launch (defaults to the job name)")
+	createReportCmd.Flags().String(reportPortalTokenParamName, "", "Report Portal API token (can be also provided via "+reportPortalTokenEnv+" env var)")
+	createReportCmd.Flags().String(knownIssuesParamName, "", "Path to a YAML file with known issue rules (under the \"knownIssues\" key), used together with the rules from the config")
+	createReportCmd.Flags().Bool(downgradeKnownIssuesParamName, false, "Report failures matching a known issue as skipped, so they don't count as failures of the job")
+	createReportCmd.Flags().String(fromDirParamName, "", "Create the report from artifacts of a job stored in the given local directory (with the same layout as the job's directory in GCS, e.g. created by \"prowjob download\") instead of a prow job ID")
+	createReportCmd.Flags().Duration(slowStepThresholdParamName, 0, "Duration above which a step is highlighted as slow in the timeline (steps taking at least 20% of the job's duration are highlighted regardless)")
+	addReportFlags(createReportCmd)
 
 	_ = viper.BindPFlag(types.ArtifactDirParamName, createReportCmd.Flags().Lookup(types.ArtifactDirParamName))
 	_ = viper.BindPFlag(types.ProwJobIDParamName, createReportCmd.Flags().Lookup(types.ProwJobIDParamName))
 	_ = viper.BindPFlag(reportPortalFormatParamName, createReportCmd.Flags().Lookup(reportPortalFormatParamName))
-	_ = viper.BindPFlag(stepsToSkipParamName, createReportCmd.Flags().Lookup(stepsToSkipParamName))
+	for _, name := range []string{reportPortalURLParamName, reportPortalProjectParamName, reportPortalLaunchNameParamName, reportPortalTokenParamName} {
+		_ = viper.BindPFlag(name, createReportCmd.Flags().Lookup(name))
+	}
+	_ = viper.BindPFlag(knownIssuesParamName, createReportCmd.Flags().Lookup(knownIssuesParamName))
+	_ = viper.BindPFlag(downgradeKnownIssuesParamName, createReportCmd.Flags().Lookup(downgradeKnownIssuesParamName))
+	_ = viper.BindPFlag(fromDirParamName, createReportCmd.Flags().Lookup(fromDirParamName))
+	_ = viper.BindPFlag(slowStepThresholdParamName, createReportCmd.Flags().Lookup(slowStepThresholdParamName))
 	// Bind environment variables to viper (in case the associated command's parameter is not provided)
 	_ = viper.BindEnv(types.ProwJobIDParamName, types.ProwJobIDEnv)
 	_ = viper.BindEnv(types.ArtifactDirParamName, types.ArtifactDirEnv)
+	_ = viper.BindEnv(reportPortalTokenParamName, reportPortalTokenEnv)
 }
@@ -0,0 +1,113 @@
+package prowjob
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+	"github.com/redhat-appstudio/qe-tools/pkg/types"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestCreateReportFromDir creates the report of the job stored in testdata/create-report/job
+// and compares the produced files with the golden files in testdata/create-report/golden
+func TestCreateReportFromDir(t *testing.T) {
+	t.Setenv(types.JobSpecEnv, "")
+	artifactDir := t.TempDir()
+
+	ProwjobCmd.SetArgs([]string{"create-report", "--from-dir", filepath.Join("testdata", "create-report", "job"), "--" + types.ArtifactDirParamName, artifactDir})
+	if err := ProwjobCmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("create-report failed: %+v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(artifactDir, htmlReportFilename)); err != nil {
+		t.Errorf("HTML report not created: %+v", err)
+	}
+
+	goldenDir := filepath.Join("testdata", "create-report", "golden")
+	for _, name := range []string{junitReportFilename, jsonReportFilename, markdownReportFilename, timelineFilename} {
+		got, err := os.ReadFile(filepath.Join(artifactDir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %+v", name, err)
+		}
+
+		goldenPath := filepath.Join(goldenDir, name)
+		if *update {
+			if err := os.WriteFile(goldenPath, got, 0o600); err != nil {
+				t.Fatalf("failed to update golden file %s: %+v", goldenPath, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("failed to read golden file (run the test with -update to create it): %+v", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs from %s (run the test with -update to update it):\n%s", name, goldenPath, got)
+		}
+	}
+}
+
+// TestCreateReportJobTargetOutsideRepoRoot checks that the built-in job target rules apply even if the command
+// doesn't run from the repository root (where the default config is located). The job has multiple target
+// directories, so its target cannot be detected from the artifacts
+func TestCreateReportJobTargetOutsideRepoRoot(t *testing.T) {
+	jobDir := filepath.Join(t.TempDir(), "job")
+	for _, name := range []string{
+		"prowjob.json",
+		"artifacts/ci-operator.log",
+		"artifacts/appstudio-e2e-tests/step-a/finished.json",
+		"artifacts/other-target/step-b/finished.json",
+	} {
+		path := filepath.Join(jobDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		content := "{}"
+		if name == "prowjob.json" {
+			content = `{"spec": {"job": "pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests"}}`
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(jobDir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	createReportConfig = CreateReportConfig{}
+	if err := readCreateReportConfig(); err != nil {
+		t.Fatalf("failed to read config: %+v", err)
+	}
+	cfg, err := newReportScannerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobDirectoryPrefix, err := prow.LocalJobDirectoryPrefix(jobDir, cfg.Instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ArtifactSource = prow.NewLocalJobSource(jobDir, jobDirectoryPrefix)
+	cfg.ProwJobURL = cfg.Instance.ProwJobURL(jobDirectoryPrefix)
+
+	scanner, err := prow.NewArtifactScanner(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scanner.Run(context.Background()); err != nil {
+		t.Fatalf("failed to scan artifacts: %+v", err)
+	}
+	if want := jobDirectoryPrefix + "/artifacts/appstudio-e2e-tests/"; scanner.ArtifactDirectoryPrefix != want {
+		t.Errorf("artifact directory prefix = %q, want %q", scanner.ArtifactDirectoryPrefix, want)
+	}
+}
@@ -0,0 +1,128 @@
+package prowjob
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/redhat-appstudio/qe-tools/pkg/junitdiff"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+// diffReportSide represents one of the compared job runs
+type diffReportSide struct {
+	suites *reporters.JUnitTestSuites
+	// steps maps names of the openshift-ci steps to their result
+	steps map[string]bool
+}
+
+// diffReportCmd represents the diff-report command
+var diffReportCmd = &cobra.Command{
+	Use:   "diff-report <base> <head>",
+	Short: "Compare test and step results of two prow jobs",
+	Long: `This command compares test results of two prow jobs (e.g. of a failed PR job and the latest green periodic job)
+and lists tests that are newly failing, newly passing, still failing, added and removed in the head job,
+as well as openshift-ci steps with different results.
+Both <base> and <head> are either prow job IDs, or paths to local JUnit reports (junit.xml created by create-report).`,
+	Args: cobra.ExactArgs(2),
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		bindSharedFlags(cmd, outputJSONParamName)
+		bindSharedFlags(cmd, reportParamNames...)
+		return readCreateReportConfig()
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, err := loadDiffReportSide(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		head, err := loadDiffReportSide(cmd, args[1])
+		if err != nil {
+			return err
+		}
+
+		result := junitdiff.Diff(withoutOpenshiftCISuite(base.suites), withoutOpenshiftCISuite(head.suites))
+		result.Steps = junitdiff.DiffSteps(base.steps, head.steps)
+
+		if viper.GetBool(outputJSONParamName) {
+			o, err := json.MarshalIndent(result, "", "    ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal diff: %+v", err)
+			}
+			fmt.Println(string(o))
+			return nil
+		}
+
+		printTestList("Newly failing tests", result.NewlyFailing)
+		printTestList("Newly passing tests", result.NewlyPassing)
+		printTestList("Still failing tests", result.StillFailing)
+		printTestList("Added tests", result.Added)
+		printTestList("Removed tests", result.Removed)
+		fmt.Printf("Steps with different results (%d):\n", len(result.Steps))
+		for _, s := range result.Steps {
+			fmt.Printf("  %s: %s -> %s\n", s.Name, s.Base, s.Head)
+		}
+		return nil
+	},
+}
+
+// loadDiffReportSide reads the JUnit report from the given file, or creates the report of the prow job with the given ID.
+// Results of steps of a local report are taken from its "openshift-ci job" suite
+func loadDiffReportSide(cmd *cobra.Command, arg string) (*diffReportSide, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(filepath.Clean(arg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JUnit report %q: %+v", arg, err)
+		}
+		suites := &reporters.JUnitTestSuites{}
+		if err := xml.Unmarshal(data, suites); err != nil {
+			return nil, fmt.Errorf("failed to decode JUnit report %q: %+v", arg, err)
+		}
+
+		side := &diffReportSide{suites: suites, steps: map[string]bool{}}
+		for _, suite := range suites.TestSuites {
+			if suite.Name != openshiftCITestSuiteName {
+				continue
+			}
+			for _, tc := range suite.TestCases {
+				side.steps[tc.Name] = tc.Failure == nil && tc.Error == nil
+			}
+		}
+		return side, nil
+	}
+
+	cfg, err := newReportScannerConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ProwJobID = arg
+
+	klog.Infof("creating report of the prow job %s", arg)
+	report, err := createJobReport(cmd.Context(), cfg, newReportOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report for prow job %s: %+v", arg, err)
+	}
+
+	side := &diffReportSide{suites: report.suites, steps: map[string]bool{}}
+	for _, step := range report.jobRun.Steps {
+		side.steps[string(step.Name)] = step.Passed
+	}
+	return side, nil
+}
+
+func printTestList(title string, tests []string) {
+	fmt.Printf("%s (%d):\n", title, len(tests))
+	for _, t := range tests {
+		fmt.Printf("  %s\n", t)
+	}
+}
+
+func init() {
+	diffReportCmd.Flags().BoolVar(&outputJSON, outputJSONParamName, false, "Print the diff in JSON format")
+	addReportFlags(diffReportCmd)
+}
@@ -0,0 +1,106 @@
+package prowjob
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+	"github.com/redhat-appstudio/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	prowJobURLParamName  string = "prow-job-url"
+	includeParamName     string = "include"
+	excludeParamName     string = "exclude"
+	includeRuleParamName string = "include-rule"
+	excludeRuleParamName string = "exclude-rule"
+)
+
+var (
+	prowJobURL      string
+	includePatterns []string
+	excludePatterns []string
+	includeRules    []string
+	excludeRules    []string
+)
+
+// downloadCmd represents the download command
+var downloadCmd = &cobra.Command{
+	Use:   "download",
+	Short: "Download artifacts of the specified prow job into a local directory",
+	Long: `This command downloads artifacts of the specified prow job into a local directory, keeping their layout.
+Artifacts that were already downloaded (with the same generation, or the same size if the generation isn't known) are skipped.
+
+Artifacts can be selected using --include and --exclude regular expressions matched against the full object name,
+or using --include-rule and --exclude-rule rules of the form "<field><operator><value>":
+  path=~<regex>, name=~<regex>, step=~<regex>  the full object name, file name or step name matches the regular expression
+  path=<glob>, name=<glob>, step=<glob>        the value matches the glob pattern ("*", "**" and "?" are supported)
+  size>N, size>=N, size<N, size<=N             the size in bytes (optionally with a Ki, Mi or Gi suffix)
+Step rules don't match files that don't belong to any step, e.g. files stored directly within the "artifacts/" directory.`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		bindSharedFlags(cmd, types.ProwJobIDParamName, types.ArtifactDirParamName, concurrencyParamName, objectTimeoutParamName, excludeRuleParamName)
+		if viper.GetString(types.ProwJobIDParamName) == "" && viper.GetString(prowJobURLParamName) == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("neither parameter %q nor %q provided, neither %s env var was set", types.ProwJobIDParamName, prowJobURLParamName, types.ProwJobIDEnv)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		instance, err := getProwInstance()
+		if err != nil {
+			return err
+		}
+
+		cfg := prow.ScannerConfig{
+			Instance:              instance,
+			ProwJobID:             viper.GetString(types.ProwJobIDParamName),
+			ProwJobURL:            viper.GetString(prowJobURLParamName),
+			FileNameFilter:        viper.GetStringSlice(includeParamName),
+			FileNameExcludeFilter: viper.GetStringSlice(excludeParamName),
+			IncludeRules:          viper.GetStringSlice(includeRuleParamName),
+			ExcludeRules:          viper.GetStringSlice(excludeRuleParamName),
+			Concurrency:           viper.GetInt(concurrencyParamName),
+			ObjectTimeout:         viper.GetDuration(objectTimeoutParamName),
+		}
+
+		scanner, err := prow.NewArtifactScanner(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+		}
+
+		artifactDir := viper.GetString(types.ArtifactDirParamName)
+		if artifactDir == "" {
+			artifactDir = "./tmp/artifacts"
+			klog.Warningf("path to artifact dir was not provided - using default %q\n", artifactDir)
+		}
+
+		result, err := scanner.Download(cmd.Context(), artifactDir)
+		if err != nil {
+			return fmt.Errorf("failed to download artifacts: %+v", err)
+		}
+		klog.Infof("artifacts saved to: %s (downloaded: %d, skipped: %d)", artifactDir, result.Downloaded, result.Skipped)
+
+		return nil
+	},
+}
+
+func init() {
+	downloadCmd.Flags().StringVar(&prowJobID, types.ProwJobIDParamName, "", "Prow job ID to download artifacts of")
+	downloadCmd.Flags().StringVar(&prowJobURL, prowJobURLParamName, "", "Prow job URL to download artifacts of (alternative to --"+types.ProwJobIDParamName+")")
+	downloadCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store downloaded artifacts")
+	downloadCmd.Flags().StringArrayVar(&includePatterns, includeParamName, nil, "Regular expression of artifacts to download (can be repeated, all artifacts are downloaded by default)")
+	downloadCmd.Flags().StringArrayVar(&excludePatterns, excludeParamName, nil, "Regular expression of artifacts to skip (can be repeated)")
+	downloadCmd.Flags().StringArrayVar(&includeRules, includeRuleParamName, nil, "Rule selecting artifacts to download, e.g. 'name=*.xml' or 'step=~e2e' (can be repeated, all artifacts are downloaded by default)")
+	downloadCmd.Flags().StringArrayVar(&excludeRules, excludeRuleParamName, nil, "Rule selecting artifacts to skip, e.g. 'size>100Mi' (can be repeated)")
+	downloadCmd.Flags().IntVar(&concurrency, concurrencyParamName, 10, "Maximum number of artifacts downloaded in parallel")
+	downloadCmd.Flags().DurationVar(&objectTimeout, objectTimeoutParamName, time.Minute, "Maximum time spent on downloading a single artifact")
+
+	_ = viper.BindPFlag(prowJobURLParamName, downloadCmd.Flags().Lookup(prowJobURLParamName))
+	_ = viper.BindPFlag(includeParamName, downloadCmd.Flags().Lookup(includeParamName))
+	_ = viper.BindPFlag(excludeParamName, downloadCmd.Flags().Lookup(excludeParamName))
+	_ = viper.BindPFlag(includeRuleParamName, downloadCmd.Flags().Lookup(includeRuleParamName))
+}
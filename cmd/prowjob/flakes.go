@@ -0,0 +1,122 @@
+package prowjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"github.com/redhat-appstudio/qe-tools/pkg/flakes"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	windowParamName string = "window"
+)
+
+var (
+	flakesJobNames []string
+	flakesWindow   time.Duration
+)
+
+// flakesCmd represents the flakes command
+var flakesCmd = &cobra.Command{
+	Use:   "flakes",
+	Short: "Detect flaky tests in the latest runs of the specified prow jobs",
+	Long: `This command analyzes JUnit reports of the latest runs of the specified prow jobs and lists tests
+that both passed and failed on the same revision, or in runs started within the --window.
+Tests are sorted by their flake rate (the ratio of failed runs to all runs of the test).`,
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		bindSharedFlags(cmd, jobNameParamName, lastParamName, outputJSONParamName)
+		bindSharedFlags(cmd, reportParamNames...)
+		if len(viper.GetStringSlice(jobNameParamName)) == 0 {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided", jobNameParamName)
+		}
+		return readCreateReportConfig()
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := newReportScannerConfig()
+		if err != nil {
+			return err
+		}
+
+		var results []flakes.TestResult
+		for _, name := range viper.GetStringSlice(jobNameParamName) {
+			jobRuns, err := listJobRuns(cmd.Context(), name, viper.GetInt(lastParamName))
+			if err != nil {
+				return err
+			}
+
+			for i, jobRun := range jobRuns {
+				jobCfg := cfg
+				jobCfg.ProwJobURL = jobRun.URL
+
+				klog.Infof("analyzing the run %s of the job %s (%d/%d)", jobRun.ID, name, i+1, len(jobRuns))
+				report, err := createJobReport(cmd.Context(), jobCfg, newReportOptions())
+				if err != nil {
+					klog.Warningf("skipping the run %s of the job %s: %+v", jobRun.ID, name, err)
+					continue
+				}
+
+				run := flakes.Run{JobName: name, ID: jobRun.ID, URL: jobRun.URL, Revision: jobRun.Revision, Started: jobRun.Started}
+				results = append(results, flakes.ResultsFromJUnit(withoutOpenshiftCISuite(report.suites), run)...)
+			}
+		}
+
+		flakyTests := flakes.Detect(results, flakes.Options{Window: viper.GetDuration(windowParamName)})
+
+		if viper.GetBool(outputJSONParamName) {
+			o, err := json.MarshalIndent(flakyTests, "", "    ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal flaky tests: %+v", err)
+			}
+			fmt.Println(string(o))
+			return nil
+		}
+
+		if len(flakyTests) == 0 {
+			fmt.Println("No flaky tests found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TEST\tFLAKE RATE\tFAILED/RUNS\tFIRST SEEN\tLAST SEEN\tFAILED RUNS")
+		for _, f := range flakyTests {
+			urls := make([]string, 0, len(f.FailedRuns))
+			for _, r := range f.FailedRuns {
+				urls = append(urls, r.URL)
+			}
+			fmt.Fprintf(w, "%s\t%.0f%%\t%d/%d\t%s\t%s\t%s\n", f.Test, f.FlakeRate*100, f.Failures, f.Runs,
+				f.FirstSeen.UTC().Format(time.RFC3339), f.LastSeen.UTC().Format(time.RFC3339), strings.Join(urls, " "))
+		}
+		return w.Flush()
+	},
+}
+
+// withoutOpenshiftCISuite returns the JUnit suites without the suite containing results of openshift-ci steps
+func withoutOpenshiftCISuite(suites *reporters.JUnitTestSuites) *reporters.JUnitTestSuites {
+	filtered := &reporters.JUnitTestSuites{}
+	for _, suite := range suites.TestSuites {
+		if suite.Name != openshiftCITestSuiteName {
+			filtered.TestSuites = append(filtered.TestSuites, suite)
+		}
+	}
+	return filtered
+}
+
+func init() {
+	flakesCmd.Flags().StringArrayVar(&flakesJobNames, jobNameParamName, nil, "Name of the prow job to analyze (can be repeated)")
+	flakesCmd.Flags().IntVar(&last, lastParamName, 10, "Number of the latest runs of each job to analyze")
+	flakesCmd.Flags().DurationVar(&flakesWindow, windowParamName, 24*time.Hour, "Maximum time between a passed and a failed run of a test to consider the test flaky (0 to only compare runs on the same revision)")
+	flakesCmd.Flags().BoolVar(&outputJSON, outputJSONParamName, false, "Print the flaky tests in JSON format")
+	addReportFlags(flakesCmd)
+
+	_ = viper.BindPFlag(windowParamName, flakesCmd.Flags().Lookup(windowParamName))
+}
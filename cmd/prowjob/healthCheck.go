@@ -64,6 +64,7 @@ var healthCheckCmd = &cobra.Command{
 	Short: `Perform a health check on dependant services`,
 	Long:  healthCheckCmdLongDescription,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		bindSharedFlags(cmd, types.ArtifactDirParamName)
 		if viper.ConfigFileUsed() == "" {
 			viper.SetConfigFile(healthCheckDefaultConfigPath)
 		}
@@ -0,0 +1,88 @@
+package prowjob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	jobNameParamName    string = "job-name"
+	lastParamName       string = "last"
+	outputJSONParamName string = "json"
+)
+
+var (
+	jobName    string
+	last       int
+	outputJSON bool
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the latest runs of the specified prow job",
+	PreRunE: func(cmd *cobra.Command, _ []string) error {
+		bindSharedFlags(cmd, jobNameParamName, lastParamName, outputJSONParamName)
+		if viper.GetString(jobNameParamName) == "" {
+			_ = cmd.Usage()
+			return fmt.Errorf("parameter %q not provided", jobNameParamName)
+		}
+		return nil
+	},
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		jobRuns, err := listJobRuns(cmd.Context(), viper.GetString(jobNameParamName), viper.GetInt(lastParamName))
+		if err != nil {
+			return err
+		}
+
+		if viper.GetBool(outputJSONParamName) {
+			o, err := json.MarshalIndent(jobRuns, "", "    ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal job runs: %+v", err)
+			}
+			fmt.Println(string(o))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BUILD ID\tRESULT\tSTARTED\tDURATION\tURL")
+		for _, jobRun := range jobRuns {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", jobRun.ID, jobRun.Result, jobRun.Started.UTC().Format(time.RFC3339), jobRun.Duration(), jobRun.URL)
+		}
+		return w.Flush()
+	},
+}
+
+// listJobRuns returns the last 'count' runs of the job with the given name, starting with the latest one
+func listJobRuns(ctx context.Context, jobName string, count int) ([]prow.JobRun, error) {
+	instance, err := getProwInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := prow.NewArtifactScanner(prow.ScannerConfig{Instance: instance})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+
+	jobRuns, err := scanner.ListJobRuns(ctx, jobName, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs of the job %s: %+v", jobName, err)
+	}
+	return jobRuns, nil
+}
+
+func init() {
+	listCmd.Flags().StringVar(&jobName, jobNameParamName, "", "Name of the prow job")
+	listCmd.Flags().IntVar(&last, lastParamName, 1, "Number of the latest job runs to list")
+	listCmd.Flags().BoolVar(&outputJSON, outputJSONParamName, false, "Print the job runs in JSON format")
+}
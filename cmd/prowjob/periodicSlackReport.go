@@ -1,6 +1,7 @@
 package prowjob
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,15 +9,25 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/redhat-appstudio/qe-tools/pkg/classification"
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
 )
 
+// defaultClassifier classifies failures of periodic jobs using the default classification rules
+var defaultClassifier = classification.MustNewClassifier(nil)
+
 // periodicReportCmd returns the periodic-report command
 var periodicReportCmd = &cobra.Command{
 	Use:   "periodic-report",
 	Short: "Analyzes the build log from latest ci jobs and returns a short job summary",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		bindSharedFlags(cmd, jobNameParamName)
+		if viper.GetString(jobNameParamName) != "" {
+			return nil
+		}
 		requiredEnvVars := []string{"prow_url"}
 
 		for _, e := range requiredEnvVars {
@@ -79,7 +90,7 @@ func extractTestResultsAndSummary(body string) string {
 	matches := regexp.MustCompile(pattern).FindStringSubmatch(body)
 
 	if matches == nil {
-		return "Infrastructure setup issues or failures unrelated to tests were found\n"
+		return fmt.Sprintf("Infrastructure setup issues or failures unrelated to tests were found (failure category: %s)\n", defaultClassifier.ClassifyStep("", body))
 	}
 
 	return fmt.Sprintf("Test Results: %s Passed | %s Failed | %s Pending | %s Skipped\nRan %s of %s Specs in %s seconds\n",
@@ -111,10 +122,43 @@ func formatFailures(failures string) string {
 	return formattedFailures.String()
 }
 
+// fetchBuildLog returns the root build log of the job with the given URL. Build logs of jobs
+// stored in the bucket of the selected Prow instance are read directly from the storage
+func fetchBuildLog(ctx context.Context, jobURL string) (string, error) {
+	instance, err := getProwInstance()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := instance.JobDirectoryPrefix(jobURL); err != nil {
+		klog.Infof("URL %q doesn't point to the bucket of the %q prow instance - fetching the build log via HTTP", jobURL, instance.Name)
+		return fetchTextContent(jobURL + "/" + buildLogFilename)
+	}
+
+	scanner, err := prow.NewArtifactScanner(prow.ScannerConfig{Instance: instance, ProwJobURL: jobURL})
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+	buildLog, err := scanner.ReadJobFile(ctx, buildLogFilename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read build log of the job %s: %+v", jobURL, err)
+	}
+
+	return removeANSIEscapeSequences(string(buildLog)), nil
+}
+
 func run(cmd *cobra.Command, args []string) error {
 	// Required GCS build.log PATH for latest build
-	prowURL := os.Getenv("PROW_URL") + "/build-log.txt"
-	bodyString, err := fetchTextContent(prowURL)
+	jobURL := os.Getenv("PROW_URL")
+	if name := viper.GetString(jobNameParamName); name != "" {
+		jobRuns, err := listJobRuns(cmd.Context(), name, 1)
+		if err != nil {
+			return err
+		}
+		jobURL = jobRuns[0].URL
+	}
+
+	bodyString, err := fetchBuildLog(cmd.Context(), jobURL)
 	if err != nil {
 		return err
 	}
@@ -123,3 +167,7 @@ func run(cmd *cobra.Command, args []string) error {
 	fmt.Println(message)
 	return nil
 }
+
+func init() {
+	periodicReportCmd.Flags().StringVar(&jobName, jobNameParamName, "", "Name of the periodic job to analyze the latest run of (alternative to PROW_URL env var)")
+}
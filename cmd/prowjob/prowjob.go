@@ -1,13 +1,21 @@
 package prowjob
 
 import (
+	"fmt"
+
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
 	"github.com/redhat-appstudio/qe-tools/pkg/types"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 const (
 	failIfUnhealthyParamName string = "fail-if-unhealthy"
 	notifyOnPRParamName      string = "notify-on-pr"
+	prowInstanceParamName    string = "prow-instance"
+
+	// prowInstancesConfigKey is the config key holding a list of custom Prow instances
+	prowInstancesConfigKey string = "prowInstances"
 )
 
 var (
@@ -15,6 +23,7 @@ var (
 	failIfUnhealthy bool
 	notifyOnPR      bool
 	prowJobID       string
+	prowInstance    string
 )
 
 // ProwjobCmd represents the prowjob command
@@ -27,7 +36,45 @@ func init() {
 	ProwjobCmd.AddCommand(periodicReportCmd)
 	ProwjobCmd.AddCommand(createReportCmd)
 	ProwjobCmd.AddCommand(healthCheckCmd)
+	ProwjobCmd.AddCommand(listCmd)
+	ProwjobCmd.AddCommand(downloadCmd)
+	ProwjobCmd.AddCommand(aggregateReportCmd)
+	ProwjobCmd.AddCommand(flakesCmd)
+	ProwjobCmd.AddCommand(diffReportCmd)
 
 	createReportCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store produced files")
 	healthCheckCmd.Flags().StringVar(&artifactDir, types.ArtifactDirParamName, "", "Path to the folder where to store produced files")
+
+	ProwjobCmd.PersistentFlags().StringVar(&prowInstance, prowInstanceParamName, prow.OpenshiftCI.Name, fmt.Sprintf("Name of the Prow instance the job belongs to - custom instances can be defined in the config under %q key", prowInstancesConfigKey))
+	_ = viper.BindPFlag(prowInstanceParamName, ProwjobCmd.PersistentFlags().Lookup(prowInstanceParamName))
+}
+
+// bindSharedFlags binds flags defined by multiple commands to viper once the command is executed,
+// so that the value of the executed command's flag isn't overridden by a flag of another command
+func bindSharedFlags(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		_ = viper.BindPFlag(name, cmd.Flags().Lookup(name))
+	}
+}
+
+// getProwInstance returns the Prow instance selected via --prow-instance flag (or config),
+// looking it up among instances defined in the config and the built-in ones
+func getProwInstance() (prow.Instance, error) {
+	name := viper.GetString(prowInstanceParamName)
+
+	var instances []prow.Instance
+	if err := viper.UnmarshalKey(prowInstancesConfigKey, &instances); err != nil {
+		return prow.Instance{}, fmt.Errorf("failed to parse %q from config: %+v", prowInstancesConfigKey, err)
+	}
+	instances = append(instances, prow.OpenshiftCI)
+
+	for _, instance := range instances {
+		if instance.Name == name {
+			if instance.BucketName == "" || instance.ProwURL == "" {
+				return prow.Instance{}, fmt.Errorf("prow instance %q has to specify both bucketName and prowURL", name)
+			}
+			return instance, nil
+		}
+	}
+	return prow.Instance{}, fmt.Errorf("unknown prow instance %q", name)
 }
@@ -0,0 +1,550 @@
+package prowjob
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+	"github.com/redhat-appstudio-qe/junit2html/pkg/convert"
+	"github.com/redhat-appstudio/qe-tools/pkg/classification"
+	"github.com/redhat-appstudio/qe-tools/pkg/clustering"
+	"github.com/redhat-appstudio/qe-tools/pkg/knownissues"
+	"github.com/redhat-appstudio/qe-tools/pkg/logexcerpt"
+	"github.com/redhat-appstudio/qe-tools/pkg/markdown"
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+	reportschema "github.com/redhat-appstudio/qe-tools/pkg/report"
+	"github.com/redhat-appstudio/qe-tools/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	junitReportFilename    = "junit.xml"
+	htmlReportFilename     = "junit-summary.html"
+	markdownReportFilename = "junit-summary.md"
+	jsonReportFilename     = "report.json"
+
+	failureGroupsTestSuiteName = "failure groups"
+
+	logContextLinesParamName   = "log-context-lines"
+	logExcerptMaxSizeParamName = "log-excerpt-max-size"
+)
+
+var (
+	logContextLines   int
+	logExcerptMaxSize int
+)
+
+// reportParamNames are the names of the flags registered by addReportFlags
+var reportParamNames = []string{stepsToSkipParamName, excludeRuleParamName, concurrencyParamName, objectTimeoutParamName,
+	spoolDirParamName, maxInMemorySizeParamName, cacheDirParamName, cacheMaxSizeParamName, layoutParamName,
+	logContextLinesParamName, logExcerptMaxSizeParamName}
+
+// reportOptions configure how the report of a Prow job is created from its artifacts
+type reportOptions struct {
+	// logExcerpt configures excerpts of build logs of failed openshift-ci steps
+	logExcerpt logexcerpt.Options
+}
+
+// jobReport represents the JUnit report of a single Prow job
+type jobReport struct {
+	scanner *prow.ArtifactScanner
+	jobRun  *prow.JobRun
+	suites  *reporters.JUnitTestSuites
+}
+
+// addReportFlags registers flags configuring how the artifacts of Prow jobs are scanned
+// and reported. The flags have to be bound via bindSharedFlags(cmd, reportParamNames...)
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&stepsToSkip, stepsToSkipParamName, []string{prow.ReportStepName}, "List of CI steps to skip when gathering artifacts")
+	cmd.Flags().StringArrayVar(&excludeRules, excludeRuleParamName, nil, "Rule selecting artifacts to skip, e.g. 'step=gather-*' or 'size>100Mi' (can be repeated, see the help of the download command for the syntax)")
+	cmd.Flags().IntVar(&concurrency, concurrencyParamName, 10, "Maximum number of artifacts downloaded in parallel")
+	cmd.Flags().DurationVar(&objectTimeout, objectTimeoutParamName, time.Minute, "Maximum time spent on downloading a single artifact")
+	cmd.Flags().StringVar(&spoolDir, spoolDirParamName, "", "Path to the folder where to store artifacts bigger than --"+maxInMemorySizeParamName+" instead of holding them in memory")
+	cmd.Flags().Int64Var(&maxInMemorySize, maxInMemorySizeParamName, 10<<20, "Maximum size (in bytes) of an artifact held in memory when --"+spoolDirParamName+" is set")
+	cmd.Flags().StringVar(&cacheDir, cacheDirParamName, "", "Path to the folder for caching downloaded artifacts between runs (caching is disabled by default)")
+	cmd.Flags().Int64Var(&cacheMaxSize, cacheMaxSizeParamName, 1<<30, "Maximum size (in bytes) of the cache folder, least recently used artifacts are evicted once exceeded")
+	cmd.Flags().StringVar(&layout, layoutParamName, string(prow.LayoutAuto), fmt.Sprintf("Layout of the job's artifacts - %q (artifacts of steps stored in artifacts/<target>/<step>/), %q (steps stored in artifacts/<step>/) or %q (detected from the job)", prow.LayoutCIOperator, prow.LayoutGeneric, prow.LayoutAuto))
+	cmd.Flags().IntVar(&logContextLines, logContextLinesParamName, 10, "Number of lines kept around each error in excerpts of build logs of failed steps")
+	cmd.Flags().IntVar(&logExcerptMaxSize, logExcerptMaxSizeParamName, 64<<10, "Maximum size (in bytes) of an excerpt of a build log of a failed step")
+}
+
+// newReportOptions returns the options of creating reports of Prow jobs
+func newReportOptions() reportOptions {
+	return reportOptions{
+		logExcerpt: logexcerpt.Options{
+			ContextLines: viper.GetInt(logContextLinesParamName),
+			MaxSize:      viper.GetInt(logExcerptMaxSizeParamName),
+		},
+	}
+}
+
+// newReportScannerConfig returns the configuration of the artifact scanner
+// collecting artifacts required for creating reports of Prow jobs
+func newReportScannerConfig() (prow.ScannerConfig, error) {
+	instance, err := getProwInstance()
+	if err != nil {
+		return prow.ScannerConfig{}, err
+	}
+
+	return prow.ScannerConfig{
+		Instance:        instance,
+		FileNameFilter:  []string{startedFilename, finishedFilename, buildLogFilename, types.JunitFilename},
+		JobTargetRules:  createReportConfig.JobTargets,
+		StepsToSkip:     viper.GetStringSlice(stepsToSkipParamName),
+		ExcludeRules:    viper.GetStringSlice(excludeRuleParamName),
+		Concurrency:     viper.GetInt(concurrencyParamName),
+		ObjectTimeout:   viper.GetDuration(objectTimeoutParamName),
+		SpoolDir:        viper.GetString(spoolDirParamName),
+		MaxInMemorySize: viper.GetInt64(maxInMemorySizeParamName),
+		CacheDir:        viper.GetString(cacheDirParamName),
+		CacheMaxSize:    viper.GetInt64(cacheMaxSizeParamName),
+		Layout:          prow.ArtifactLayout(viper.GetString(layoutParamName)),
+	}, nil
+}
+
+// createJobReport scans the artifacts of the Prow job specified in the ScannerConfig and creates its JUnit report.
+// The report contains JUnit suites found in the job's artifacts and the "openshift-ci job" suite with results of the job's steps.
+// Test cases of failed steps contain excerpts of the steps' build logs with a link to the full log
+func createJobReport(ctx context.Context, cfg prow.ScannerConfig, opts reportOptions) (*jobReport, error) {
+	scanner, err := prow.NewArtifactScanner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize artifact scanner: %+v", err)
+	}
+
+	if err := scanner.Run(ctx); err != nil {
+		return nil, fmt.Errorf("failed to scan artifacts: %+v", err)
+	}
+
+	overallJUnitSuites := &reporters.JUnitTestSuites{}
+	openshiftCiJunit := reporters.JUnitTestSuite{Name: openshiftCITestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
+
+	htmlReportLink := scanner.BrowserURL(scanner.ArtifactDirectoryPrefix + prow.ReportStepName + "/artifacts/" + htmlReportFilename)
+	openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: "html-report-link", Value: htmlReportLink})
+	if warning := incompleteReportWarning(scanner); warning != "" {
+		klog.Warning(warning)
+		openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: "incomplete-report", Value: warning})
+	}
+
+	jobRun, err := scanner.GetJobRun(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata of the job: %+v", err)
+	}
+
+	for _, step := range jobRun.Steps {
+		artifactsFilenameMap := scanner.ArtifactStepMap[step.Name]
+		if strings.Contains(string(step.Name), "gather") {
+			openshiftCiJunit.Properties.Properties = append(openshiftCiJunit.Properties.Properties, reporters.JUnitProperty{Name: string(step.Name), Value: scanner.BrowserURL(strings.TrimSuffix(artifactsFilenameMap[finishedFilename].FullName, finishedFilename) + "artifacts")})
+		}
+
+		if step.Passed {
+			openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, reporters.JUnitTestCase{Name: string(step.Name), Status: ginkgoTypes.SpecStatePassed.String(), Time: step.Duration().Seconds()})
+		} else {
+			var buildLog string
+			if val, ok := artifactsFilenameMap[buildLogFilename]; ok {
+				buildLog = buildLogExcerpt(scanner, val, opts.logExcerpt)
+			}
+
+			failure := &reporters.JUnitFailure{Message: fmt.Sprintf("%s has failed", step.Name)}
+			tc := reporters.JUnitTestCase{Name: string(step.Name), Status: ginkgoTypes.SpecStateFailed.String(), Time: step.Duration().Seconds(), Failure: failure, SystemErr: buildLog}
+			openshiftCiJunit.Failures++
+			openshiftCiJunit.TestCases = append(openshiftCiJunit.TestCases, tc)
+		}
+		openshiftCiJunit.Tests++
+	}
+
+	// Decode JUnit suites in the order of their steps and file names, so that the report is deterministic
+	stepNames := make([]string, 0, len(scanner.ArtifactStepMap))
+	for stepName := range scanner.ArtifactStepMap {
+		stepNames = append(stepNames, string(stepName))
+	}
+	sort.Strings(stepNames)
+	for _, stepName := range stepNames {
+		artifactsFilenameMap := scanner.ArtifactStepMap[prow.ArtifactStepName(stepName)]
+		artifactFilenames := make([]string, 0, len(artifactsFilenameMap))
+		for artifactFilename := range artifactsFilenameMap {
+			artifactFilenames = append(artifactFilenames, string(artifactFilename))
+		}
+		sort.Strings(artifactFilenames)
+		for _, artifactFilename := range artifactFilenames {
+			artifact := artifactsFilenameMap[prow.ArtifactFilename(artifactFilename)]
+			if strings.Contains(artifactFilename, ".xml") {
+				rc, err := artifact.Open()
+				if err != nil {
+					return nil, err
+				}
+				if err = xml.NewDecoder(rc).Decode(overallJUnitSuites); err != nil {
+					klog.Errorf("cannot decode JUnit suite %q into xml: %+v", artifactFilename, err)
+				}
+				rc.Close()
+			}
+		}
+	}
+
+	// Add timestamp to openshift-ci job. It's left empty if the start of the job is unknown,
+	// so that reports created from the same artifacts don't differ
+	if len(overallJUnitSuites.TestSuites) > 0 {
+		openshiftCiJunit.Timestamp = overallJUnitSuites.TestSuites[0].Timestamp
+	} else if !jobRun.Started.IsZero() {
+		openshiftCiJunit.Timestamp = jobRun.Started.UTC().Format("2006-01-02T15:04:05")
+	}
+
+	overallJUnitSuites.TestSuites = append(overallJUnitSuites.TestSuites, openshiftCiJunit)
+	overallJUnitSuites.Failures += openshiftCiJunit.Failures
+	overallJUnitSuites.Errors += openshiftCiJunit.Errors
+	overallJUnitSuites.Tests += openshiftCiJunit.Tests
+
+	// Omit system-err from passed test cases
+	for i := range overallJUnitSuites.TestSuites {
+		for j := range overallJUnitSuites.TestSuites[i].TestCases {
+			tc := &overallJUnitSuites.TestSuites[i].TestCases[j]
+			if tc.Status == "passed" {
+				tc.SystemErr = ""
+			}
+		}
+	}
+
+	return &jobReport{scanner: scanner, jobRun: jobRun, suites: overallJUnitSuites}, nil
+}
+
+// incompleteReportWarning returns a line saying that the report is incomplete
+// if some of the job's artifacts couldn't be downloaded, an empty string otherwise
+func incompleteReportWarning(scanner *prow.ArtifactScanner) string {
+	if len(scanner.SkippedArtifacts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("the report is incomplete, %d artifact(s) couldn't be downloaded: %s", len(scanner.SkippedArtifacts), strings.Join(scanner.SkippedArtifacts, ", "))
+}
+
+// buildLogExcerpt returns the relevant parts of the build log artifact, preceded by a link to the full log.
+// A log that cannot be read doesn't prevent creating the report, only the link is returned then
+func buildLogExcerpt(scanner *prow.ArtifactScanner, artifact prow.Artifact, opts logexcerpt.Options) string {
+	link := fmt.Sprintf("Full log: %s", scanner.BrowserURL(artifact.FullName))
+
+	rc, err := artifact.Open()
+	if err != nil {
+		klog.Warningf("cannot open build log %s: %+v", artifact.FullName, err)
+		return link
+	}
+	defer rc.Close()
+
+	excerpt, err := logexcerpt.Extract(rc, opts)
+	if err != nil {
+		klog.Warningf("failed to extract excerpt of build log %s: %+v", artifact.FullName, err)
+		return link
+	}
+	return link + "\n\n" + excerpt
+}
+
+// writeJUnitReport stores the JUnit suites in the junit.xml file and their HTML summary in the junit-summary.html file
+// within the given directory. The given HTML sections are inserted at the beginning of the summary's body
+func writeJUnitReport(suites *reporters.JUnitTestSuites, artifactDir string, htmlSections ...string) error {
+	if err := os.MkdirAll(artifactDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for results '%s': %+v", artifactDir, err)
+	}
+
+	generatedJunitFilepath := filepath.Join(artifactDir, junitReportFilename)
+	if err := writeXMLFile(generatedJunitFilepath, suites); err != nil {
+		return err
+	}
+
+	htmlReport, err := convert.Convert(suites)
+	if err != nil {
+		return fmt.Errorf("failed to convert junit suite to html: %+v", err)
+	}
+	if len(htmlSections) > 0 {
+		htmlReport = strings.Replace(htmlReport, "<body>", "<body>"+strings.Join(htmlSections, ""), 1)
+	}
+	if err := os.WriteFile(filepath.Join(artifactDir, htmlReportFilename), []byte(htmlReport), 0o600); err != nil {
+		return fmt.Errorf("failed to create HTML file with test summary: %+v", err)
+	}
+
+	klog.Infof("JUnit report saved to: %s", generatedJunitFilepath)
+	klog.Infof("HTML report saved to: %s", filepath.Join(artifactDir, htmlReportFilename))
+	return nil
+}
+
+// writeMarkdownReport stores the Markdown report of the job (suitable for GitHub PR comments)
+// in the junit-summary.md file within the given directory
+func writeMarkdownReport(report *jobReport, artifactDir string, knownIssueMatches []knownissues.Match, failureClassification string) error {
+	var steps []markdown.Step
+	for _, step := range report.jobRun.Steps {
+		s := markdown.Step{Name: string(step.Name), Passed: step.Passed, Duration: step.Duration()}
+		if finished, ok := report.scanner.ArtifactStepMap[step.Name][finishedFilename]; ok {
+			s.ArtifactsURL = report.scanner.BrowserURL(strings.TrimSuffix(finished.FullName, finishedFilename) + "artifacts")
+		}
+		steps = append(steps, s)
+	}
+
+	var issues []markdown.KnownIssue
+	for _, m := range knownIssueMatches {
+		issues = append(issues, markdown.KnownIssue{Test: m.Suite + " / " + m.Test, Issue: m.Rule.Issue, URL: m.Rule.URL, Note: m.Rule.Note})
+	}
+
+	md := markdown.Render(markdown.Report{
+		Title:          fmt.Sprintf("Test report of %s #%s", report.jobRun.JobName, report.jobRun.ID),
+		JobURL:         report.jobRun.URL,
+		HTMLReportURL:  report.scanner.BrowserURL(report.scanner.ArtifactDirectoryPrefix + prow.ReportStepName + "/artifacts/" + htmlReportFilename),
+		Suites:         report.suites,
+		Steps:          steps,
+		KnownIssues:    issues,
+		Classification: failureClassification,
+		Warning:        incompleteReportWarning(report.scanner),
+	})
+
+	path := filepath.Join(artifactDir, markdownReportFilename)
+	if err := os.WriteFile(path, []byte(md), 0o600); err != nil {
+		return fmt.Errorf("failed to create Markdown report: %+v", err)
+	}
+	klog.Infof("Markdown report saved to: %s", path)
+	return nil
+}
+
+// getJobSpecOfJobRun returns the job spec from the JOB_SPEC env var, or nil if the env var isn't set
+// or if it describes a job run other than the one with the given ID (e.g. when reporting another job)
+func getJobSpecOfJobRun(buildID string) (*prow.OpenshiftJobSpec, error) {
+	jobSpecData := os.Getenv(types.JobSpecEnv)
+	if jobSpecData == "" {
+		return nil, nil
+	}
+	jobSpec, err := prow.ParseJobSpec(jobSpecData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s env var: %+v", types.JobSpecEnv, err)
+	}
+	if jobSpec.BuildID != buildID {
+		klog.Infof("ignoring %s env var - it describes the build %q, not %q", types.JobSpecEnv, jobSpec.BuildID, buildID)
+		return nil, nil
+	}
+	return jobSpec, nil
+}
+
+// newJSONReport returns the machine-readable report of the job. Refs of the job are taken from its prowjob.json,
+// or from the JOB_SPEC env var (set by openshift-ci) if the job doesn't have any and the env var describes the same job run
+func newJSONReport(report *jobReport) (reportschema.Report, error) {
+	jobRun := report.jobRun
+	job := reportschema.Job{
+		ID:              jobRun.ID,
+		Name:            jobRun.JobName,
+		Type:            jobRun.Type,
+		URL:             jobRun.URL,
+		Result:          jobRun.Result,
+		Started:         reportschema.TimePtr(jobRun.Started),
+		Finished:        reportschema.TimePtr(jobRun.Finished),
+		DurationSeconds: jobRun.Duration().Seconds(),
+		Revision:        jobRun.Revision,
+	}
+	if refs := jobRun.Refs; refs != nil {
+		job.Refs = &reportschema.Refs{Organization: refs.Org, Repo: refs.Repo, RepoLink: refs.RepoLink, BaseRef: refs.BaseRef, BaseSHA: refs.BaseSHA}
+		for _, p := range refs.Pulls {
+			job.Refs.Pulls = append(job.Refs.Pulls, reportschema.Pull{Number: p.Number, Author: p.Author, SHA: p.SHA, Link: p.Link, AuthorLink: p.AuthorLink})
+		}
+	} else if jobSpec, err := getJobSpecOfJobRun(jobRun.ID); err != nil {
+		return reportschema.Report{}, err
+	} else if jobSpec != nil {
+		if job.Type == "" {
+			job.Type = jobSpec.Type
+		}
+		refs := jobSpec.Refs
+		job.Refs = &reportschema.Refs{Organization: refs.Organization, Repo: refs.Repo, RepoLink: refs.RepoLink}
+		for _, p := range refs.Pulls {
+			job.Refs.Pulls = append(job.Refs.Pulls, reportschema.Pull{Number: p.Number, Author: p.Author, SHA: p.SHA, Link: p.PRLink, AuthorLink: p.AuthorLink})
+		}
+	}
+
+	steps := []reportschema.Step{}
+	for _, step := range jobRun.Steps {
+		s := reportschema.Step{
+			Name:            string(step.Name),
+			Status:          reportschema.StatusPassed,
+			Result:          step.Result,
+			Started:         reportschema.TimePtr(step.Started),
+			Finished:        reportschema.TimePtr(step.Finished),
+			DurationSeconds: step.Duration().Seconds(),
+		}
+		switch {
+		case step.Result == prow.ResultPending:
+			s.Status = reportschema.StatusPending
+		case !step.Passed:
+			s.Status = reportschema.StatusFailed
+		}
+		artifacts := report.scanner.ArtifactStepMap[step.Name]
+		if finished, ok := artifacts[finishedFilename]; ok {
+			s.ArtifactsURL = report.scanner.BrowserURL(strings.TrimSuffix(finished.FullName, finishedFilename) + "artifacts")
+		}
+		if buildLog, ok := artifacts[buildLogFilename]; ok {
+			s.BuildLogURL = report.scanner.BrowserURL(buildLog.FullName)
+		}
+		steps = append(steps, s)
+	}
+
+	return reportschema.Report{
+		SchemaVersion: reportschema.SchemaVersion,
+		Job:           job,
+		Steps:         steps,
+		Tests:         reportschema.TestsFromJUnit(withoutOpenshiftCISuite(report.suites)),
+	}, nil
+}
+
+// writeXMLFile encodes the given value into the XML file located at the given path
+func writeXMLFile(path string, v any) error {
+	outFile, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("cannot create file '%s': %+v", path, err)
+	}
+	defer outFile.Close()
+
+	if err := xml.NewEncoder(outFile).Encode(v); err != nil {
+		return fmt.Errorf("cannot encode struct into file located at '%s': %+v", path, err)
+	}
+	return outFile.Close()
+}
+
+// writeJSONFile encodes the given value into the JSON file located at the given path
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return fmt.Errorf("cannot marshal %s: %+v", path, err)
+	}
+	if err := os.WriteFile(filepath.Clean(path), data, 0o600); err != nil {
+		return fmt.Errorf("cannot write file '%s': %+v", path, err)
+	}
+	klog.Infof("%s saved to: %s", filepath.Base(path), path)
+	return nil
+}
+
+// applyKnownIssues matches failures of the JUnit suites against the known issue rules from the create-report config
+// and the file specified via --known-issues, see knownissues.Matcher.Annotate. Returns the matches and the HTML section
+// listing them (an empty string if there are no matches)
+func applyKnownIssues(suites *reporters.JUnitTestSuites) ([]knownissues.Match, string, error) {
+	rules := createReportConfig.KnownIssues
+	if path := viper.GetString(knownIssuesParamName); path != "" {
+		fileRules, err := knownissues.LoadFile(path)
+		if err != nil {
+			return nil, "", err
+		}
+		rules = append(rules, fileRules...)
+	}
+	if len(rules) == 0 {
+		return nil, "", nil
+	}
+
+	matcher, err := knownissues.NewMatcher(rules)
+	if err != nil {
+		return nil, "", err
+	}
+	matches := matcher.Annotate(suites, viper.GetBool(downgradeKnownIssuesParamName))
+	if len(matches) == 0 {
+		return nil, "", nil
+	}
+
+	var sb strings.Builder
+	writeHTMLTableStart(&sb, "Known issues", "Test", "Issue", "Note")
+	for _, m := range matches {
+		issue := html.EscapeString(m.Rule.Issue)
+		if m.Rule.URL != "" {
+			issue = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(m.Rule.URL), issue)
+		}
+		fmt.Fprintf(&sb, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>", html.EscapeString(m.Suite+" / "+m.Test), issue, html.EscapeString(m.Rule.Note))
+	}
+	sb.WriteString("</table>")
+	return matches, sb.String(), nil
+}
+
+// classifyFailures labels failed openshift-ci steps and tests of the JUnit suites with the category of their failure
+// using the classification rules from the create-report config and the default ones. Categories are added
+// to the properties of the suites ("failure-category: <test>" => "<category>"), the summary of the categories
+// is added to the properties of the "openshift-ci job" suite ("failure-classification") and returned
+func classifyFailures(suites *reporters.JUnitTestSuites) (string, error) {
+	classifier, err := classification.NewClassifier(createReportConfig.FailureCategories)
+	if err != nil {
+		return "", fmt.Errorf("invalid failure classification rules: %+v", err)
+	}
+
+	var categories []classification.Category
+	for i := range suites.TestSuites {
+		suite := &suites.TestSuites[i]
+		for _, tc := range suite.TestCases {
+			var log string
+			switch {
+			case tc.Failure != nil:
+				log = tc.Failure.Message + "\n" + tc.Failure.Description
+			case tc.Error != nil:
+				log = tc.Error.Message + "\n" + tc.Error.Description
+			default:
+				continue
+			}
+			log += "\n" + tc.SystemErr
+
+			category := classifier.ClassifyTest(log)
+			if suite.Name == openshiftCITestSuiteName {
+				category = classifier.ClassifyStep(tc.Name, log)
+			}
+			categories = append(categories, category)
+			suite.Properties.Properties = append(suite.Properties.Properties, reporters.JUnitProperty{Name: "failure-category: " + tc.Name, Value: string(category)})
+		}
+	}
+
+	summary := classification.Summary(categories)
+	for i := range suites.TestSuites {
+		if suites.TestSuites[i].Name == openshiftCITestSuiteName {
+			suites.TestSuites[i].Properties.Properties = append(suites.TestSuites[i].Properties.Properties, reporters.JUnitProperty{Name: "failure-classification", Value: summary})
+		}
+	}
+	klog.Infof("failure classification: %s", summary)
+	return summary, nil
+}
+
+// addFailureGroups groups failed test cases of the JUnit suites by their normalized failure message
+// and adds the "failure groups" suite describing the groups via its properties. The suite doesn't
+// contain any test cases, so it doesn't affect the totals of the report. Returns the HTML section
+// listing the groups, or an empty string if there are no failures
+func addFailureGroups(suites *reporters.JUnitTestSuites) string {
+	groups := clustering.GroupJUnitFailures(suites)
+	if len(groups) == 0 {
+		return ""
+	}
+
+	suite := reporters.JUnitTestSuite{Name: failureGroupsTestSuiteName, Properties: reporters.JUnitProperties{Properties: []reporters.JUnitProperty{}}}
+	var sb strings.Builder
+	writeHTMLTableStart(&sb, "Failure groups", "Failures", "Failure message", "Tests")
+	for i, g := range groups {
+		tests := make([]string, 0, len(g.Failures))
+		for _, f := range g.Failures {
+			tests = append(tests, f.Suite+" / "+f.Test)
+		}
+		suite.Properties.Properties = append(suite.Properties.Properties,
+			reporters.JUnitProperty{Name: fmt.Sprintf("failure-group-%d", i+1), Value: fmt.Sprintf("%d tests failed with: %s", len(g.Failures), g.Signature)},
+			reporters.JUnitProperty{Name: fmt.Sprintf("failure-group-%d-tests", i+1), Value: strings.Join(tests, "\n")})
+
+		fmt.Fprintf(&sb, "<tr><td>%d</td><td><pre style=\"white-space: pre-wrap\">%s</pre></td><td><details><summary>%d tests</summary><ul>",
+			len(g.Failures), html.EscapeString(g.Signature), len(g.Failures))
+		for _, t := range tests {
+			fmt.Fprintf(&sb, "<li>%s</li>", html.EscapeString(t))
+		}
+		sb.WriteString("</ul></details></td></tr>")
+	}
+	sb.WriteString("</table>")
+
+	suites.TestSuites = append(suites.TestSuites, suite)
+	return sb.String()
+}
+
+// Helper function to start a section of the HTML report with the given heading, containing a table
+// with the given columns. The table has to be closed by the caller
+func writeHTMLTableStart(sb *strings.Builder, heading string, columns ...string) {
+	fmt.Fprintf(sb, `<h2>%s</h2><table border="1" cellpadding="4" style="border-collapse: collapse; margin-bottom: 2em"><tr>`, html.EscapeString(heading))
+	for _, c := range columns {
+		fmt.Fprintf(sb, "<th>%s</th>", html.EscapeString(c))
+	}
+	sb.WriteString("</tr>")
+}
@@ -0,0 +1,69 @@
+package prowjob
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	reportschema "github.com/redhat-appstudio/qe-tools/pkg/report"
+	"github.com/redhat-appstudio/qe-tools/pkg/reportportal"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+)
+
+const (
+	reportPortalURLParamName        = "report-portal-url"
+	reportPortalProjectParamName    = "report-portal-project"
+	reportPortalLaunchNameParamName = "report-portal-launch-name"
+	reportPortalTokenParamName      = "report-portal-token"
+
+	reportPortalTokenEnv = "REPORT_PORTAL_TOKEN" // #nosec G101
+)
+
+// uploadToReportPortal reports the JUnit suites of the job as a new launch to the Report Portal instance
+// specified via --report-portal-url
+func uploadToReportPortal(ctx context.Context, jsonReport reportschema.Report, suites *reporters.JUnitTestSuites) error {
+	client := &reportportal.Client{
+		BaseURL: viper.GetString(reportPortalURLParamName),
+		Project: viper.GetString(reportPortalProjectParamName),
+		Token:   viper.GetString(reportPortalTokenParamName),
+	}
+	if client.Project == "" {
+		return fmt.Errorf("parameter %q has to be provided together with %q", reportPortalProjectParamName, reportPortalURLParamName)
+	}
+
+	launch := newReportPortalLaunch(jsonReport.Job, viper.GetString(reportPortalLaunchNameParamName))
+	launchID, err := reportportal.ImportJUnit(ctx, client, launch, suites)
+	if err != nil {
+		return fmt.Errorf("failed to upload report to Report Portal: %+v", err)
+	}
+	klog.Infof("report uploaded to Report Portal as launch %s", launchID)
+	return nil
+}
+
+// newReportPortalLaunch returns the launch reporting the given job. Attributes of the launch describe the job
+// (repo, PR number, job type and name). The launch is named after the job unless 'name' is specified
+func newReportPortalLaunch(job reportschema.Job, name string) reportportal.Launch {
+	launch := reportportal.Launch{
+		Name:        name,
+		Description: job.URL,
+		Attributes:  []reportportal.Attribute{{Key: "job-name", Value: job.Name}, {Key: "prow-job-id", Value: job.ID}},
+	}
+	if launch.Name == "" {
+		launch.Name = job.Name
+	}
+	if job.Started != nil {
+		launch.StartTime = *job.Started
+	}
+	if job.Type != "" {
+		launch.Attributes = append(launch.Attributes, reportportal.Attribute{Key: "job-type", Value: job.Type})
+	}
+	if job.Refs != nil {
+		launch.Attributes = append(launch.Attributes, reportportal.Attribute{Key: "repo", Value: job.Refs.Organization + "/" + job.Refs.Repo})
+		if len(job.Refs.Pulls) > 0 {
+			launch.Attributes = append(launch.Attributes, reportportal.Attribute{Key: "pr-number", Value: strconv.Itoa(job.Refs.Pulls[0].Number)})
+		}
+	}
+	return launch
+}
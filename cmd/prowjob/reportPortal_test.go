@@ -0,0 +1,72 @@
+package prowjob
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	reportschema "github.com/redhat-appstudio/qe-tools/pkg/report"
+	"github.com/redhat-appstudio/qe-tools/pkg/reportportal"
+)
+
+func TestNewReportPortalLaunch(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		job        reportschema.Job
+		launchName string
+		want       reportportal.Launch
+	}{
+		{
+			name: "presubmit",
+			job: reportschema.Job{
+				ID: "123", Name: "pull-ci-org-repo-main-e2e", Type: "presubmit", URL: "https://prow/123", Started: &started,
+				Refs: &reportschema.Refs{Organization: "org", Repo: "repo", Pulls: []reportschema.Pull{{Number: 42}}},
+			},
+			want: reportportal.Launch{
+				Name:        "pull-ci-org-repo-main-e2e",
+				Description: "https://prow/123",
+				StartTime:   started,
+				Attributes: []reportportal.Attribute{
+					{Key: "job-name", Value: "pull-ci-org-repo-main-e2e"},
+					{Key: "prow-job-id", Value: "123"},
+					{Key: "job-type", Value: "presubmit"},
+					{Key: "repo", Value: "org/repo"},
+					{Key: "pr-number", Value: "42"},
+				},
+			},
+		},
+		{
+			name:       "periodic with launch name",
+			job:        reportschema.Job{ID: "456", Name: "periodic-e2e", Type: "periodic", URL: "https://prow/456", Refs: &reportschema.Refs{Organization: "org", Repo: "repo"}},
+			launchName: "nightly",
+			want: reportportal.Launch{
+				Name:        "nightly",
+				Description: "https://prow/456",
+				Attributes: []reportportal.Attribute{
+					{Key: "job-name", Value: "periodic-e2e"},
+					{Key: "prow-job-id", Value: "456"},
+					{Key: "job-type", Value: "periodic"},
+					{Key: "repo", Value: "org/repo"},
+				},
+			},
+		},
+		{
+			name: "without type and refs",
+			job:  reportschema.Job{ID: "789", Name: "local-job"},
+			want: reportportal.Launch{
+				Name:       "local-job",
+				Attributes: []reportportal.Attribute{{Key: "job-name", Value: "local-job"}, {Key: "prow-job-id", Value: "789"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newReportPortalLaunch(tt.job, tt.launchName); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("newReportPortalLaunch() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
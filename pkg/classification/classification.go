@@ -0,0 +1,166 @@
+package classification
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Category describes the cause of a failure
+type Category string
+
+const (
+	ClusterProvisioning   Category = "cluster provisioning"
+	ExternalServiceOutage Category = "external-service outage"
+	TestFailure           Category = "test failure"
+	Unknown               Category = "unknown"
+)
+
+// categoryPriority orders categories by how much they explain the failure of the whole job -
+// tests can't pass on a cluster that wasn't provisioned or without the services they depend on
+var categoryPriority = []Category{ClusterProvisioning, ExternalServiceOutage, TestFailure, Unknown}
+
+// Rule assigns the category to failures matching its patterns. All patterns specified
+// by the rule have to match the failure, at least one pattern has to be specified
+type Rule struct {
+	Category Category `json:"category"`
+	// Step is a regular expression matched against the name of the failed openshift-ci step
+	Step string `json:"step"`
+	// Log is a regular expression matched against the failure message and the log excerpt
+	Log string `json:"log"`
+}
+
+// DefaultRules recognize common causes of failures of openshift-ci jobs. Rules matching logs come first,
+// so that e.g. an outage of an external service during the tests isn't classified as a test failure
+var DefaultRules = []Rule{
+	{Category: ExternalServiceOutage, Log: `(?i)(503 Service Unavailable|502 Bad Gateway|504 Gateway Time-?out|toomanyrequests|rate limit exceeded|TLS handshake timeout|no such host|manifest unknown)`},
+	{Category: ExternalServiceOutage, Log: `(?i)(quay\.io|registry\.redhat\.io|registry\.access\.redhat\.com|github\.com|gitlab\.com).*(connection refused|connection reset|i/o timeout|timed out|unavailable|status code 5\d\d)`},
+	{Category: ClusterProvisioning, Log: `(?i)(failed to acquire lease|failed to create (the )?cluster|cluster (creation|installation|provisioning) failed|bootstrap failed to complete|insufficient quota|quota exceeded for)`},
+	{Category: ClusterProvisioning, Step: `^(ipi|upi)-|(^|-)cluster-(provision|create|install)|^hypershift-.*-(create|install)|(^|-)provision($|-)`},
+	// Gather and post steps collect data from the test cluster and tear it down, they fail if the cluster is broken
+	{Category: ClusterProvisioning, Step: `^gather-|(^|-)(must-gather|deprovision|destroy)($|-)|-post$`},
+	{Category: TestFailure, Step: `(^|-)(e2e|tests?)($|-)`},
+}
+
+type matcher struct {
+	category Category
+	step     *regexp.Regexp
+	log      *regexp.Regexp
+}
+
+// Classifier assigns categories to failures of steps and tests
+type Classifier struct {
+	matchers []matcher
+}
+
+// NewClassifier compiles the given rules, followed by the DefaultRules
+func NewClassifier(rules []Rule) (*Classifier, error) {
+	c := &Classifier{}
+	for _, rule := range append(append([]Rule{}, rules...), DefaultRules...) {
+		if rule.Category == "" {
+			return nil, fmt.Errorf("classification rule %+v doesn't specify the category", rule)
+		}
+		if !slices.Contains(categoryPriority, rule.Category) {
+			return nil, fmt.Errorf("classification rule %+v specifies unknown category %q, expected one of %q", rule, rule.Category, categoryPriority)
+		}
+		if rule.Step == "" && rule.Log == "" {
+			return nil, fmt.Errorf("classification rule for %q has to specify a step or log pattern", rule.Category)
+		}
+
+		m := matcher{category: rule.Category}
+		var err error
+		if rule.Step != "" {
+			if m.step, err = regexp.Compile(rule.Step); err != nil {
+				return nil, fmt.Errorf("invalid step pattern %q for category %q: %+v", rule.Step, rule.Category, err)
+			}
+		}
+		if rule.Log != "" {
+			if m.log, err = regexp.Compile(rule.Log); err != nil {
+				return nil, fmt.Errorf("invalid log pattern %q for category %q: %+v", rule.Log, rule.Category, err)
+			}
+		}
+		c.matchers = append(c.matchers, m)
+	}
+	return c, nil
+}
+
+// MustNewClassifier is like NewClassifier but panics if any of the rules is invalid.
+// It simplifies initialization of global variables holding classifiers
+func MustNewClassifier(rules []Rule) *Classifier {
+	c, err := NewClassifier(rules)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// ClassifyStep returns the category of the failure of the step with the given name and log (excerpt)
+func (c *Classifier) ClassifyStep(step, log string) Category {
+	for _, m := range c.matchers {
+		if (m.step == nil || m.step.MatchString(step)) && (m.log == nil || m.log.MatchString(log)) {
+			return m.category
+		}
+	}
+	return Unknown
+}
+
+// ClassifyTest returns the category of the failure of a test with the given failure message and log (excerpt).
+// Only rules matching logs are applied, failures that don't match any of them are test failures
+func (c *Classifier) ClassifyTest(log string) Category {
+	for _, m := range c.matchers {
+		if m.step == nil && m.log != nil && m.log.MatchString(log) {
+			return m.category
+		}
+	}
+	return TestFailure
+}
+
+// Overall returns the category that explains the failure of the whole job best, or an empty
+// category if there are no failures. Categories are ordered by the priority: cluster provisioning,
+// external-service outage, test failure and unknown
+func Overall(categories []Category) Category {
+	var overall Category
+	for _, category := range categories {
+		if overall == "" || priority(category) < priority(overall) {
+			overall = category
+		}
+	}
+	return overall
+}
+
+// Summary returns a line summarizing the categories of failures, e.g.
+// "cluster provisioning (1 cluster provisioning, 3 test failure)"
+func Summary(categories []Category) string {
+	if len(categories) == 0 {
+		return "no failures"
+	}
+
+	counts := map[Category]int{}
+	var order []Category
+	for _, category := range categories {
+		if counts[category] == 0 {
+			order = append(order, category)
+		}
+		counts[category]++
+	}
+	sort.SliceStable(order, func(i, j int) bool { return priority(order[i]) < priority(order[j]) })
+
+	parts := make([]string, 0, len(order))
+	for _, category := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[category], category))
+	}
+	return fmt.Sprintf("%s (%s)", Overall(categories), strings.Join(parts, ", "))
+}
+
+// Helper function to return the priority of the category
+func priority(category Category) int {
+	for i, c := range categoryPriority {
+		if c == category {
+			return i
+		}
+	}
+	return len(categoryPriority)
+}
@@ -0,0 +1,88 @@
+package classification
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	classifier, err := NewClassifier(nil)
+	if err != nil {
+		t.Fatalf("failed to compile the default rules: %+v", err)
+	}
+
+	steps := []struct {
+		step string
+		log  string
+		want Category
+	}{
+		{step: "ipi-install-install", want: ClusterProvisioning},
+		{step: "upi-conf-gcp", want: ClusterProvisioning},
+		{step: "hypershift-aws-create", want: ClusterProvisioning},
+		{step: "redhat-appstudio-cluster-provision", want: ClusterProvisioning},
+		{step: "gather-extra", want: ClusterProvisioning},
+		{step: "gather-must-gather", want: ClusterProvisioning},
+		{step: "ipi-deprovision-deprovision", want: ClusterProvisioning},
+		{step: "hypershift-aws-destroy", want: ClusterProvisioning},
+		{step: "redhat-appstudio-post", want: ClusterProvisioning},
+		{step: "redhat-appstudio-e2e", want: TestFailure},
+		{step: "integration-tests", want: TestFailure},
+		{step: "redhat-appstudio-e2e", log: "failed to pull image: 503 Service Unavailable", want: ExternalServiceOutage},
+		{step: "redhat-appstudio-e2e", log: "Get https://quay.io/v2/: dial tcp: i/o timeout", want: ExternalServiceOutage},
+		{step: "redhat-appstudio-e2e", log: "toomanyrequests: You have reached your pull rate limit", want: ExternalServiceOutage},
+		{step: "redhat-appstudio-e2e", log: "failed to acquire lease for aws-quota-slice", want: ClusterProvisioning},
+		{step: "redhat-appstudio-setup", log: "error: exit status 1", want: Unknown},
+		// Words merely containing the step patterns don't match
+		{step: "postgres-setup", want: Unknown},
+		{step: "provisioner-setup", want: Unknown},
+	}
+	for _, tt := range steps {
+		t.Run(tt.step+" "+tt.log, func(t *testing.T) {
+			if got := classifier.ClassifyStep(tt.step, tt.log); got != tt.want {
+				t.Errorf("ClassifyStep(%q, %q) = %q, want %q", tt.step, tt.log, got, tt.want)
+			}
+		})
+	}
+
+	tests := []struct {
+		log  string
+		want Category
+	}{
+		{log: "Expected <bool>: false to be true", want: TestFailure},
+		{log: "github.com: connection reset by peer", want: ExternalServiceOutage},
+		{log: "cluster installation failed", want: ClusterProvisioning},
+	}
+	for _, tt := range tests {
+		t.Run(tt.log, func(t *testing.T) {
+			if got := classifier.ClassifyTest(tt.log); got != tt.want {
+				t.Errorf("ClassifyTest(%q) = %q, want %q", tt.log, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClassifierValidatesRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr string
+	}{
+		{name: "valid", rule: Rule{Category: ExternalServiceOutage, Log: "registry down"}},
+		{name: "missing category", rule: Rule{Log: "x"}, wantErr: "doesn't specify the category"},
+		{name: "unknown category", rule: Rule{Category: "infra", Log: "x"}, wantErr: `unknown category "infra"`},
+		{name: "misspelled category", rule: Rule{Category: "test failures", Step: "e2e"}, wantErr: `unknown category "test failures"`},
+		{name: "missing patterns", rule: Rule{Category: Unknown}, wantErr: "has to specify a step or log pattern"},
+		{name: "invalid pattern", rule: Rule{Category: TestFailure, Step: "("}, wantErr: "invalid step pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClassifier([]Rule{tt.rule})
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %+v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
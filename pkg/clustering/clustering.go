@@ -0,0 +1,105 @@
+package clustering
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Variable parts of failure messages, replaced by placeholders when computing the message signature
+var (
+	uuidRegexp      = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	timestampRegexp = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	timeRegexp      = regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(\.\d+)?\b`)
+	// Names of pods created by deployments ("<name>-<hash>-<suffix>") and other controllers ("<name>-<suffix>").
+	// Generated parts consist only of the characters Kubernetes uses for random strings (no vowels, 0, 1 and 3)
+	podNameRegexp = regexp.MustCompile(`\b([a-z0-9]+(?:-[a-z0-9]+)*?)(-[bcdfghjklmnpqrstvwxz2456789]{6,10})?-([bcdfghjklmnpqrstvwxz2456789]{5})\b`)
+	// Hex numbers ("0x...") and hashes; hashes have to contain both digits and letters to differ from decimal numbers and words
+	hexHashRegexp = regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{7,}\b`)
+	digitRegexp   = regexp.MustCompile(`\d`)
+	letterRegexp  = regexp.MustCompile(`[a-zA-Z]`)
+	spacesRegexp  = regexp.MustCompile(`\s+`)
+)
+
+// maxSignatureLength limits the length (in characters) of failure signatures
+const maxSignatureLength = 500
+
+// Failure represents a failed test case
+type Failure struct {
+	Suite   string
+	Test    string
+	Message string
+}
+
+// Group represents failures with the same signature
+type Group struct {
+	// Signature is the normalized failure message shared by all failures of the group
+	Signature string
+	Failures  []Failure
+}
+
+// Normalize returns the failure message with UUIDs, timestamps, pod names and hex hashes replaced by placeholders
+// and all whitespace collapsed, so that messages of failures with the same cause are equal
+func Normalize(message string) string {
+	message = uuidRegexp.ReplaceAllString(message, "<uuid>")
+	message = timestampRegexp.ReplaceAllString(message, "<timestamp>")
+	message = timeRegexp.ReplaceAllString(message, "<time>")
+	message = podNameRegexp.ReplaceAllStringFunc(message, func(name string) string {
+		// Generated parts contain digits, unlike regular words (e.g. "e2e-tests"), and their suffix contains letters,
+		// unlike numbers (e.g. "cve-2023-44487")
+		m := podNameRegexp.FindStringSubmatch(name)
+		if !digitRegexp.MatchString(m[2]+m[3]) || !letterRegexp.MatchString(m[3]) {
+			return name
+		}
+		return m[1] + "-<pod>"
+	})
+	message = hexHashRegexp.ReplaceAllStringFunc(message, func(hash string) string {
+		if !strings.HasPrefix(hash, "0x") && (!digitRegexp.MatchString(hash) || !letterRegexp.MatchString(hash)) {
+			return hash
+		}
+		return "<hash>"
+	})
+	return strings.TrimSpace(spacesRegexp.ReplaceAllString(message, " "))
+}
+
+// GroupJUnitFailures groups failed (and errored) test cases of the given JUnit suites by the signature
+// of their failure message. Groups are sorted by the number of failures, starting with the biggest one
+func GroupJUnitFailures(suites *reporters.JUnitTestSuites) []Group {
+	var failures []Failure
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			switch {
+			case tc.Failure != nil:
+				failures = append(failures, Failure{Suite: suite.Name, Test: tc.Name, Message: tc.Failure.Message})
+			case tc.Error != nil:
+				failures = append(failures, Failure{Suite: suite.Name, Test: tc.Name, Message: tc.Error.Message})
+			}
+		}
+	}
+	return GroupFailures(failures)
+}
+
+// GroupFailures groups the given failures by the signature of their message.
+// Groups are sorted by the number of failures, starting with the biggest one
+func GroupFailures(failures []Failure) []Group {
+	var groups []Group
+	groupIndex := map[string]int{}
+	for _, f := range failures {
+		signature := Normalize(f.Message)
+		if r := []rune(signature); len(r) > maxSignatureLength {
+			signature = string(r[:maxSignatureLength]) + "..."
+		}
+		i, ok := groupIndex[signature]
+		if !ok {
+			i = len(groups)
+			groupIndex[signature] = i
+			groups = append(groups, Group{Signature: signature})
+		}
+		groups[i].Failures = append(groups[i].Failures, f)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].Failures) > len(groups[j].Failures) })
+	return groups
+}
@@ -0,0 +1,93 @@
+package clustering
+
+import (
+	"reflect"
+	"testing"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "deployment pod",
+			message: `Timed out after 300.000s. pod "build-service-controller-manager-7d9f8c6b5d-x2kqz" in namespace "build-service" is not ready`,
+			want:    `Timed out after 300.000s. pod "build-service-controller-manager-<pod>" in namespace "build-service" is not ready`,
+		},
+		{
+			name:    "generated name",
+			message: `Error from server (NotFound): namespaces "e2e-tests-x7k2p" not found`,
+			want:    `Error from server (NotFound): namespaces "e2e-tests-<pod>" not found`,
+		},
+		{
+			name:    "UUID and timestamp",
+			message: "2024-01-15T10:22:33.123Z snapshot 0f8fad5b-d9cb-469f-a165-70867728950e wasn't created",
+			want:    "<timestamp> snapshot <uuid> wasn't created",
+		},
+		{
+			name:    "image digest and commit",
+			message: "failed to pull quay.io/redhat-appstudio/build-definitions@sha256:3f1c2a9e8b7d6c5f built from commit a1b2c3d",
+			want:    "failed to pull quay.io/redhat-appstudio/build-definitions@sha256:<hash> built from commit <hash>",
+		},
+		{
+			name:    "hex number",
+			message: "panic: runtime error: invalid memory address [signal SIGSEGV addr=0x0000000 pc=0x1a2b3c4d]",
+			want:    "panic: runtime error: invalid memory address [signal SIGSEGV addr=<hash> pc=<hash>]",
+		},
+		{
+			name:    "decimal numbers are kept",
+			message: "Expected <int64>: 1048576 to equal <int64>: 2097152 at 10:01:02",
+			want:    "Expected <int64>: 1048576 to equal <int64>: 2097152 at <time>",
+		},
+		{
+			name:    "words are kept",
+			message: "expected the defaced component to be effaced",
+			want:    "expected the defaced component to be effaced",
+		},
+		{
+			name:    "hyphenated words are kept",
+			message: "pipelinerun redhat-appstudio-e2e-tests for linux-arm64 via https-proxy failed with cve-2023-44487",
+			want:    "pipelinerun redhat-appstudio-e2e-tests for linux-arm64 via https-proxy failed with cve-2023-44487",
+		},
+		{
+			name:    "whitespace is collapsed",
+			message: "  failed\n\tto   reconcile \n",
+			want:    "failed to reconcile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.message); got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupJUnitFailures(t *testing.T) {
+	suites := &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{Name: "e2e", TestCases: []reporters.JUnitTestCase{
+			{Name: "a", Failure: &reporters.JUnitFailure{Message: `pod "app-5c9d8f7b6d-q2w4z" is not ready`}},
+			{Name: "b", Error: &reporters.JUnitError{Message: "exit code 1"}},
+			{Name: "c"},
+			{Name: "d", Failure: &reporters.JUnitFailure{Message: `pod "app-6f7c8d9b5d-z9x8v" is not ready`}},
+		}},
+	}}
+
+	groups := GroupJUnitFailures(suites)
+	want := []Group{
+		{Signature: `pod "app-<pod>" is not ready`, Failures: []Failure{
+			{Suite: "e2e", Test: "a", Message: `pod "app-5c9d8f7b6d-q2w4z" is not ready`},
+			{Suite: "e2e", Test: "d", Message: `pod "app-6f7c8d9b5d-z9x8v" is not ready`},
+		}},
+		{Signature: "exit code 1", Failures: []Failure{{Suite: "e2e", Test: "b", Message: "exit code 1"}}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("GroupJUnitFailures() = %+v, want %+v", groups, want)
+	}
+}
@@ -0,0 +1,63 @@
+package customjunit
+
+import (
+	"github.com/onsi/ginkgo/v2/reporters"
+)
+
+// FromGinkgo converts Ginkgo JUnit suites into the custom structures. Pending and disabled test cases
+// are reported as skipped, suite and total counts are recomputed from the states of the test cases
+func FromGinkgo(suites *reporters.JUnitTestSuites) *TestSuites {
+	custom := &TestSuites{}
+	for _, suite := range suites.TestSuites {
+		s := TestSuite{
+			Name:       suite.Name,
+			Package:    suite.Package,
+			Time:       suite.Time,
+			Timestamp:  suite.Timestamp,
+			Properties: suite.Properties,
+		}
+		for _, tc := range suite.TestCases {
+			c := TestCase{
+				Name:      tc.Name,
+				Classname: tc.Classname,
+				Time:      tc.Time,
+				Skipped:   tc.Skipped,
+				Error:     tc.Error,
+				Failure:   tc.Failure,
+				SystemOut: tc.SystemOut,
+				SystemErr: tc.SystemErr,
+			}
+
+			switch {
+			case c.Failure != nil:
+				s.Failures++
+			case c.Error != nil:
+				s.Errors++
+			case c.Skipped != nil:
+				s.Skipped++
+			default:
+				switch tc.Status {
+				case "skipped", "pending", "disabled":
+					c.Skipped = &reporters.JUnitSkipped{Message: tc.Status}
+					s.Skipped++
+				case "failed", "timedout":
+					c.Failure = &reporters.JUnitFailure{Message: tc.Status, Type: tc.Status}
+					s.Failures++
+				case "panicked", "interrupted", "aborted":
+					c.Error = &reporters.JUnitError{Message: tc.Status, Type: tc.Status}
+					s.Errors++
+				}
+			}
+			s.Tests++
+			s.TestCases = append(s.TestCases, c)
+		}
+
+		custom.Tests += s.Tests
+		custom.Skipped += s.Skipped
+		custom.Errors += s.Errors
+		custom.Failures += s.Failures
+		custom.Time += s.Time
+		custom.TestSuites = append(custom.TestSuites, s)
+	}
+	return custom
+}
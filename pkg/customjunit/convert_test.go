@@ -0,0 +1,88 @@
+package customjunit
+
+import (
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+)
+
+func TestFromGinkgo(t *testing.T) {
+	type counts struct {
+		tests, skipped, errors, failures int
+	}
+
+	tests := []struct {
+		name     string
+		testCase reporters.JUnitTestCase
+		want     counts
+		// wantElement is the element expected to be set on the converted test case ("skipped", "failure" or "error")
+		wantElement string
+	}{
+		{name: "passed", testCase: reporters.JUnitTestCase{Status: "passed"}, want: counts{tests: 1}},
+		{name: "failed", testCase: reporters.JUnitTestCase{Status: "failed"}, want: counts{tests: 1, failures: 1}, wantElement: "failure"},
+		{name: "timedout", testCase: reporters.JUnitTestCase{Status: "timedout"}, want: counts{tests: 1, failures: 1}, wantElement: "failure"},
+		{name: "panicked", testCase: reporters.JUnitTestCase{Status: "panicked"}, want: counts{tests: 1, errors: 1}, wantElement: "error"},
+		{name: "interrupted", testCase: reporters.JUnitTestCase{Status: "interrupted"}, want: counts{tests: 1, errors: 1}, wantElement: "error"},
+		{name: "aborted", testCase: reporters.JUnitTestCase{Status: "aborted"}, want: counts{tests: 1, errors: 1}, wantElement: "error"},
+		{name: "skipped", testCase: reporters.JUnitTestCase{Status: "skipped"}, want: counts{tests: 1, skipped: 1}, wantElement: "skipped"},
+		{name: "pending", testCase: reporters.JUnitTestCase{Status: "pending"}, want: counts{tests: 1, skipped: 1}, wantElement: "skipped"},
+		{name: "disabled", testCase: reporters.JUnitTestCase{Status: "disabled"}, want: counts{tests: 1, skipped: 1}, wantElement: "skipped"},
+		{
+			name:        "explicit skipped element",
+			testCase:    reporters.JUnitTestCase{Status: "passed", Skipped: &reporters.JUnitSkipped{Message: "skipped"}},
+			want:        counts{tests: 1, skipped: 1},
+			wantElement: "skipped",
+		},
+		{
+			name:        "explicit failure element",
+			testCase:    reporters.JUnitTestCase{Status: "passed", Failure: &reporters.JUnitFailure{Message: "failed"}},
+			want:        counts{tests: 1, failures: 1},
+			wantElement: "failure",
+		},
+		{
+			name:        "explicit error element",
+			testCase:    reporters.JUnitTestCase{Status: "passed", Error: &reporters.JUnitError{Message: "panicked"}},
+			want:        counts{tests: 1, errors: 1},
+			wantElement: "error",
+		},
+		{
+			name:        "explicit failure element takes precedence over status",
+			testCase:    reporters.JUnitTestCase{Status: "skipped", Failure: &reporters.JUnitFailure{Message: "failed"}},
+			want:        counts{tests: 1, failures: 1},
+			wantElement: "failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The test case is reported by two suites, so the totals have to be sums of the suites' counts.
+			// Counts of the input suites are deliberately wrong, they have to be recomputed
+			suite := reporters.JUnitTestSuite{Name: "suite", Tests: 42, Failures: 42, Time: 1.5, TestCases: []reporters.JUnitTestCase{tt.testCase}}
+			got := FromGinkgo(&reporters.JUnitTestSuites{Tests: 42, TestSuites: []reporters.JUnitTestSuite{suite, suite}})
+
+			if len(got.TestSuites) != 2 {
+				t.Fatalf("expected 2 suites, got %d", len(got.TestSuites))
+			}
+			for _, s := range got.TestSuites {
+				if c := (counts{s.Tests, s.Skipped, s.Errors, s.Failures}); c != tt.want {
+					t.Errorf("suite counts = %+v, want %+v", c, tt.want)
+				}
+			}
+			wantTotal := counts{2 * tt.want.tests, 2 * tt.want.skipped, 2 * tt.want.errors, 2 * tt.want.failures}
+			if c := (counts{got.Tests, got.Skipped, got.Errors, got.Failures}); c != wantTotal {
+				t.Errorf("total counts = %+v, want %+v", c, wantTotal)
+			}
+			if got.Time != 3 {
+				t.Errorf("total time = %v, want 3", got.Time)
+			}
+
+			tc := got.TestSuites[0].TestCases[0]
+			elements := map[string]bool{"skipped": tc.Skipped != nil, "failure": tc.Failure != nil, "error": tc.Error != nil}
+			for element, set := range elements {
+				if set != (element == tt.wantElement) {
+					t.Errorf("%s element set = %v, want element %q", element, set, tt.wantElement)
+				}
+			}
+		})
+	}
+}
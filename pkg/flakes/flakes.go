@@ -0,0 +1,138 @@
+package flakes
+
+import (
+	"sort"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Run identifies a single run of a Prow job
+type Run struct {
+	JobName  string    `json:"jobName"`
+	ID       string    `json:"id"`
+	URL      string    `json:"url"`
+	Revision string    `json:"revision,omitempty"`
+	Started  time.Time `json:"started"`
+}
+
+// TestResult represents the result of a single test in a single job run
+type TestResult struct {
+	// Test is the name of the test, prefixed with the name of its suite
+	Test   string
+	Passed bool
+	Run    Run
+}
+
+// Flake represents a test that both passed and failed on the same revision, or within a time window
+type Flake struct {
+	Test string `json:"test"`
+	// Runs is the number of runs the test was executed in
+	Runs int `json:"runs"`
+	// Failures is the number of runs the test failed in
+	Failures int `json:"failures"`
+	// FlakeRate is the ratio of Failures to Runs
+	FlakeRate float64 `json:"flakeRate"`
+	// FirstSeen and LastSeen are the start times of the first and the last run the test failed in
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	// FailedRuns are the runs the test failed in, sorted from the latest one
+	FailedRuns []Run `json:"failedRuns"`
+}
+
+// Options configure how flaky tests are detected
+type Options struct {
+	// Window is the maximum time between the start of a passed and a failed run of the test
+	// for the test to be considered flaky. If zero, only runs on the same revision are compared
+	Window time.Duration
+}
+
+// ResultsFromJUnit returns results of all test cases of the given JUnit suites executed within the given run.
+// Skipped test cases are omitted
+func ResultsFromJUnit(suites *reporters.JUnitTestSuites, run Run) []TestResult {
+	var results []TestResult
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			if tc.Skipped != nil || tc.Status == "skipped" || tc.Status == "pending" {
+				continue
+			}
+			results = append(results, TestResult{
+				Test:   suite.Name + " / " + tc.Name,
+				Passed: tc.Failure == nil && tc.Error == nil,
+				Run:    run,
+			})
+		}
+	}
+	return results
+}
+
+// Detect returns flaky tests found among the given results, sorted by their flake rate
+func Detect(results []TestResult, opts Options) []Flake {
+	resultsByTest := map[string][]TestResult{}
+	for _, r := range results {
+		resultsByTest[r.Test] = append(resultsByTest[r.Test], r)
+	}
+
+	var flakes []Flake
+	for test, testResults := range resultsByTest {
+		if !isFlaky(testResults, opts) {
+			continue
+		}
+
+		flake := Flake{Test: test, Runs: len(testResults)}
+		for _, r := range testResults {
+			if r.Passed {
+				continue
+			}
+			flake.Failures++
+			flake.FailedRuns = append(flake.FailedRuns, r.Run)
+			if flake.FirstSeen.IsZero() || r.Run.Started.Before(flake.FirstSeen) {
+				flake.FirstSeen = r.Run.Started
+			}
+			if r.Run.Started.After(flake.LastSeen) {
+				flake.LastSeen = r.Run.Started
+			}
+		}
+		flake.FlakeRate = float64(flake.Failures) / float64(flake.Runs)
+		sort.Slice(flake.FailedRuns, func(i, j int) bool { return flake.FailedRuns[i].Started.After(flake.FailedRuns[j].Started) })
+
+		flakes = append(flakes, flake)
+	}
+
+	sort.Slice(flakes, func(i, j int) bool {
+		if flakes[i].FlakeRate != flakes[j].FlakeRate {
+			return flakes[i].FlakeRate > flakes[j].FlakeRate
+		}
+		return flakes[i].Test < flakes[j].Test
+	})
+	return flakes
+}
+
+// Helper function to check whether the test passed and failed on the same revision,
+// or within the time window
+func isFlaky(results []TestResult, opts Options) bool {
+	for _, failed := range results {
+		if failed.Passed {
+			continue
+		}
+		for _, passed := range results {
+			if !passed.Passed {
+				continue
+			}
+			if failed.Run.Revision != "" && failed.Run.Revision == passed.Run.Revision {
+				return true
+			}
+			if opts.Window > 0 && absDuration(failed.Run.Started.Sub(passed.Run.Started)) <= opts.Window {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
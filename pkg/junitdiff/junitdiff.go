@@ -0,0 +1,132 @@
+package junitdiff
+
+import (
+	"sort"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Status is the result of a test or a step
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+	// StatusMissing marks a step that is not present in one of the compared job runs
+	StatusMissing Status = "missing"
+)
+
+// StepChange represents a step whose result differs between the compared job runs
+type StepChange struct {
+	Name string `json:"name"`
+	Base Status `json:"base"`
+	Head Status `json:"head"`
+}
+
+// Result contains differences between the base and the head JUnit reports.
+// Tests are identified by the name of their suite and their own name ("<suite> / <test>")
+type Result struct {
+	// NewlyFailing are tests that failed in the head, but not in the base report
+	NewlyFailing []string `json:"newlyFailing"`
+	// NewlyPassing are tests that failed in the base, but passed in the head report
+	NewlyPassing []string `json:"newlyPassing"`
+	// StillFailing are tests that failed in both reports
+	StillFailing []string `json:"stillFailing"`
+	// Added are tests that are present only in the head report
+	Added []string `json:"added"`
+	// Removed are tests that are present only in the base report
+	Removed []string     `json:"removed"`
+	Steps   []StepChange `json:"steps,omitempty"`
+}
+
+// TestStatuses returns statuses of all tests of the given JUnit suites. A test reported multiple
+// times (e.g. when it was retried) is considered failed if any of its runs failed
+func TestStatuses(suites *reporters.JUnitTestSuites) map[string]Status {
+	statuses := map[string]Status{}
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			name := suite.Name + " / " + tc.Name
+			status := testCaseStatus(tc)
+			if prev, ok := statuses[name]; ok && (prev == StatusFailed || status == StatusSkipped) {
+				continue
+			}
+			statuses[name] = status
+		}
+	}
+	return statuses
+}
+
+func testCaseStatus(tc reporters.JUnitTestCase) Status {
+	switch {
+	case tc.Failure != nil || tc.Error != nil:
+		return StatusFailed
+	case tc.Skipped != nil || tc.Status == "skipped" || tc.Status == "pending":
+		return StatusSkipped
+	default:
+		return StatusPassed
+	}
+}
+
+// Diff compares test results of the base and the head JUnit reports
+func Diff(base, head *reporters.JUnitTestSuites) Result {
+	return DiffStatuses(TestStatuses(base), TestStatuses(head))
+}
+
+// DiffStatuses compares the base and the head test statuses, all lists of the result are sorted by test name
+func DiffStatuses(base, head map[string]Status) Result {
+	r := Result{NewlyFailing: []string{}, NewlyPassing: []string{}, StillFailing: []string{}, Added: []string{}, Removed: []string{}}
+	for name, h := range head {
+		b, ok := base[name]
+		switch {
+		case !ok:
+			r.Added = append(r.Added, name)
+		case h == StatusFailed && b == StatusFailed:
+			r.StillFailing = append(r.StillFailing, name)
+		case h == StatusFailed:
+			r.NewlyFailing = append(r.NewlyFailing, name)
+		case h == StatusPassed && b == StatusFailed:
+			r.NewlyPassing = append(r.NewlyPassing, name)
+		}
+	}
+	for name := range base {
+		if _, ok := head[name]; !ok {
+			r.Removed = append(r.Removed, name)
+		}
+	}
+
+	for _, l := range [][]string{r.NewlyFailing, r.NewlyPassing, r.StillFailing, r.Added, r.Removed} {
+		sort.Strings(l)
+	}
+	return r
+}
+
+// DiffSteps returns steps whose result (passed or not) differs between the base and the head job runs,
+// including steps present only in one of them. Steps are sorted by name
+func DiffSteps(base, head map[string]bool) []StepChange {
+	var changes []StepChange
+	for name, h := range head {
+		b, ok := base[name]
+		switch {
+		case !ok:
+			changes = append(changes, StepChange{Name: name, Base: StatusMissing, Head: stepStatus(h)})
+		case b != h:
+			changes = append(changes, StepChange{Name: name, Base: stepStatus(b), Head: stepStatus(h)})
+		}
+	}
+	for name, b := range base {
+		if _, ok := head[name]; !ok {
+			changes = append(changes, StepChange{Name: name, Base: stepStatus(b), Head: StatusMissing})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func stepStatus(passed bool) Status {
+	if passed {
+		return StatusPassed
+	}
+	return StatusFailed
+}
@@ -0,0 +1,154 @@
+package knownissues
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	ginkgoTypes "github.com/onsi/ginkgo/v2/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Rule maps failures matching its patterns to a known issue. All patterns specified
+// by the rule have to match the failure, at least one pattern has to be specified
+type Rule struct {
+	// Issue is the key of the issue in the tracker, e.g. "KFLUXBUGS-123"
+	Issue string `json:"issue"`
+	// URL is the link to the issue
+	URL string `json:"url"`
+	// Note describes the issue or a workaround
+	Note string `json:"note"`
+	// Test is a regular expression matched against the name of the failed test
+	Test string `json:"test"`
+	// Message is a regular expression matched against the failure message
+	Message string `json:"message"`
+	// Log is a regular expression matched against the failure details and the log excerpt (system-err) of the test
+	Log string `json:"log"`
+}
+
+// Config is the content of the YAML file with known issues
+type Config struct {
+	KnownIssues []Rule `json:"knownIssues"`
+}
+
+// Match represents a failed test matched by a known issue rule
+type Match struct {
+	Suite string
+	Test  string
+	Rule  Rule
+}
+
+type matcher struct {
+	rule    Rule
+	test    *regexp.Regexp
+	message *regexp.Regexp
+	log     *regexp.Regexp
+}
+
+// Matcher matches failures against the known issue rules
+type Matcher struct {
+	matchers []matcher
+}
+
+// LoadFile reads known issue rules from the given YAML file
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known issues file: %+v", err)
+	}
+	cfg := Config{}
+	if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse known issues file %s: %+v", path, err)
+	}
+	return cfg.KnownIssues, nil
+}
+
+// NewMatcher compiles the patterns of the given rules
+func NewMatcher(rules []Rule) (*Matcher, error) {
+	m := &Matcher{}
+	for _, rule := range rules {
+		if rule.Issue == "" {
+			return nil, fmt.Errorf("known issue rule %+v doesn't specify the issue", rule)
+		}
+		if rule.Test == "" && rule.Message == "" && rule.Log == "" {
+			return nil, fmt.Errorf("known issue rule for %q has to specify a test, message or log pattern", rule.Issue)
+		}
+
+		compiled := matcher{rule: rule}
+		for _, p := range []struct {
+			name    string
+			pattern string
+			re      **regexp.Regexp
+		}{{"test", rule.Test, &compiled.test}, {"message", rule.Message, &compiled.message}, {"log", rule.Log, &compiled.log}} {
+			if p.pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(p.pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s pattern %q for known issue %q: %+v", p.name, p.pattern, rule.Issue, err)
+			}
+			*p.re = re
+		}
+		m.matchers = append(m.matchers, compiled)
+	}
+	return m, nil
+}
+
+// Match returns the first rule matching the failure, false if there is none
+func (m *Matcher) Match(test, message, log string) (Rule, bool) {
+	for _, c := range m.matchers {
+		if (c.test == nil || c.test.MatchString(test)) &&
+			(c.message == nil || c.message.MatchString(message)) &&
+			(c.log == nil || c.log.MatchString(log)) {
+			return c.rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Annotate matches failed (and errored) test cases of the given JUnit suites against the rules and adds
+// the matched issues to the properties of the suites ("known-issue: <test>" => "<issue> <url>").
+// If downgrade is set, the matched test cases are marked as skipped, so they don't count as failures
+func (m *Matcher) Annotate(suites *reporters.JUnitTestSuites, downgrade bool) []Match {
+	var matches []Match
+	for i := range suites.TestSuites {
+		suite := &suites.TestSuites[i]
+		for j := range suite.TestCases {
+			tc := &suite.TestCases[j]
+			var message, details string
+			switch {
+			case tc.Failure != nil:
+				message, details = tc.Failure.Message, tc.Failure.Description
+			case tc.Error != nil:
+				message, details = tc.Error.Message, tc.Error.Description
+			default:
+				continue
+			}
+
+			rule, ok := m.Match(tc.Name, message, details+"\n"+tc.SystemErr)
+			if !ok {
+				continue
+			}
+			matches = append(matches, Match{Suite: suite.Name, Test: tc.Name, Rule: rule})
+			suite.Properties.Properties = append(suite.Properties.Properties,
+				reporters.JUnitProperty{Name: "known-issue: " + tc.Name, Value: rule.Issue + " " + rule.URL})
+
+			if downgrade {
+				if tc.Failure != nil {
+					suite.Failures--
+					suites.Failures--
+				} else {
+					suite.Errors--
+					suites.Errors--
+				}
+				suite.Skipped++
+				tc.Skipped = &reporters.JUnitSkipped{Message: fmt.Sprintf("known issue %s: %s", rule.Issue, message)}
+				tc.Failure, tc.Error = nil, nil
+				tc.Status = ginkgoTypes.SpecStateSkipped.String()
+			}
+		}
+	}
+	return matches
+}
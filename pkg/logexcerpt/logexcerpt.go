@@ -0,0 +1,225 @@
+package logexcerpt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultContextLines = 10
+	defaultTailLines    = 50
+	defaultMaxSize      = 64 << 10 // 64 KiB
+	maxLineSize         = 1 << 20  // 1 MiB
+
+	separator = "..."
+	// truncatedLineSuffix is appended to lines longer than maxLineSize
+	truncatedLineSuffix = " [truncated]"
+)
+
+// DefaultMarkers match lines reporting errors in logs of openshift-ci steps
+var DefaultMarkers = []*regexp.Regexp{
+	// Ginkgo failures
+	regexp.MustCompile(`\[(FAIL|FAILED|PANICKED|TIMEDOUT)\]`),
+	// logrus/klog style errors
+	regexp.MustCompile(`level=(error|fatal)`),
+	regexp.MustCompile(`^[EF]\d{4} \d{2}:\d{2}:\d{2}`),
+	// Go panics
+	regexp.MustCompile(`^panic: `),
+	// Commands exiting with a non-zero code
+	regexp.MustCompile(`(?i)(exit (status|code)|exited with (status|code)) [1-9]\d*`),
+	regexp.MustCompile(`(?i)non-zero exit`),
+}
+
+// Options configure how the excerpt is extracted
+type Options struct {
+	// ContextLines is the number of lines kept before and after each line with an error marker (defaults to 10)
+	ContextLines int
+	// TailLines is the number of the last lines of the log returned when no error marker is found (defaults to 50)
+	TailLines int
+	// MaxSize limits the size (in bytes) of the excerpt, the latest parts of the log are preferred (defaults to 64 KiB)
+	MaxSize int
+	// Markers match lines with errors (defaults to DefaultMarkers)
+	Markers []*regexp.Regexp
+}
+
+// Extract returns the relevant parts of the log read from the given reader - lines matching any of the markers
+// surrounded by the context lines. Parts of the log that are not adjacent are separated by a line with "...".
+// If no line matches any of the markers, the last lines of the log are returned
+func Extract(r io.Reader, opts Options) (string, error) {
+	if opts.ContextLines <= 0 {
+		opts.ContextLines = defaultContextLines
+	}
+	if opts.TailLines <= 0 {
+		opts.TailLines = defaultTailLines
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultMaxSize
+	}
+	if opts.Markers == nil {
+		opts.Markers = DefaultMarkers
+	}
+
+	e := &extractor{opts: opts}
+	reader := bufio.NewReaderSize(r, 64<<10)
+	var line []byte
+	truncated := false
+	for {
+		fragment, isPrefix, err := reader.ReadLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read log: %+v", err)
+		}
+
+		// Lines longer than maxLineSize (e.g. minified JSON) are truncated, the rest of the line is dropped
+		if room := maxLineSize - len(line); room > 0 {
+			if len(fragment) > room {
+				fragment, truncated = fragment[:room], true
+			}
+			line = append(line, fragment...)
+		} else {
+			truncated = true
+		}
+		if isPrefix {
+			continue
+		}
+
+		if truncated {
+			line = append(line, truncatedLineSuffix...)
+		}
+		e.addLine(string(line))
+		line, truncated = line[:0], false
+	}
+
+	return e.excerpt(), nil
+}
+
+// extractor collects the parts of the log around lines with error markers
+type extractor struct {
+	opts Options
+	// lineNumber is the number of lines read so far
+	lineNumber int
+	// recent are the last lines preceding the current one, used as the context
+	// of the following marker (or as the tail of the log if there are no markers)
+	recent []string
+	// parts are the collected parts of the log, part is the one being collected
+	parts []string
+	part  []string
+	size  int
+	// partSize is the size of the part being collected (including line breaks)
+	partSize int
+	// remaining is the number of lines to be added to the part following the last marker
+	remaining int
+	// lastLine is the number of the last line added to the part
+	lastLine int
+	found    bool
+	// truncated is set once any of the collected parts is dropped because of the size limit
+	truncated bool
+}
+
+func (e *extractor) addLine(line string) {
+	e.lineNumber++
+
+	if e.isMarker(line) {
+		e.found = true
+		context := e.recent
+		if len(context) > e.opts.ContextLines {
+			context = context[len(context)-e.opts.ContextLines:]
+		}
+		if e.part != nil && e.lineNumber-len(context) > e.lastLine+1 {
+			// Context of this marker isn't adjacent to the current part
+			e.finishPart()
+		}
+		e.appendToPart(context...)
+		e.appendToPart(line)
+		e.recent = nil
+		e.lastLine = e.lineNumber
+		e.remaining = e.opts.ContextLines
+		return
+	}
+
+	if e.remaining > 0 {
+		e.appendToPart(line)
+		e.lastLine = e.lineNumber
+		e.remaining--
+		return
+	}
+
+	e.recent = append(e.recent, line)
+	keep := e.opts.ContextLines
+	if !e.found && e.opts.TailLines > keep {
+		keep = e.opts.TailLines
+	}
+	if len(e.recent) > keep {
+		e.recent = e.recent[len(e.recent)-keep:]
+	}
+}
+
+// Helper function to add lines to the current part, dropping its earliest lines
+// if the size limit is exceeded (e.g. when markers keep following each other)
+func (e *extractor) appendToPart(lines ...string) {
+	for _, line := range lines {
+		e.part = append(e.part, line)
+		e.partSize += len(line) + 1
+	}
+	for len(e.part) > 1 && e.partSize > e.opts.MaxSize {
+		e.partSize -= len(e.part[0]) + 1
+		e.part = e.part[1:]
+		e.truncated = true
+	}
+}
+
+func (e *extractor) isMarker(line string) bool {
+	for _, m := range e.opts.Markers {
+		if m.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to add the current part to the collected parts,
+// dropping the earliest parts if the size limit is exceeded
+func (e *extractor) finishPart() {
+	if e.part == nil {
+		return
+	}
+	p := strings.Join(e.part, "\n")
+	e.parts = append(e.parts, p)
+	e.size += len(p) + len(separator) + 2
+	e.part = nil
+	e.partSize = 0
+
+	for len(e.parts) > 1 && e.size > e.opts.MaxSize {
+		e.size -= len(e.parts[0]) + len(separator) + 2
+		e.parts = e.parts[1:]
+		e.truncated = true
+	}
+}
+
+func (e *extractor) excerpt() string {
+	if !e.found {
+		e.part = e.recent
+		if len(e.part) > e.opts.TailLines {
+			e.part = e.part[len(e.part)-e.opts.TailLines:]
+		}
+	}
+	e.finishPart()
+
+	excerpt := strings.Join(e.parts, "\n"+separator+"\n")
+	if len(excerpt) > e.opts.MaxSize {
+		excerpt = excerpt[len(excerpt)-e.opts.MaxSize:]
+		if i := strings.IndexByte(excerpt, '\n'); i >= 0 {
+			excerpt = excerpt[i+1:]
+		}
+		e.truncated = true
+	}
+	if e.truncated {
+		return separator + "\n" + excerpt
+	}
+	return excerpt
+}
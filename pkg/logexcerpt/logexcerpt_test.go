@@ -0,0 +1,80 @@
+package logexcerpt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Helper function to create a log with the given number of lines "line <n>"
+func numberedLines(from, to int) []string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	return lines
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		log  []string
+		opts Options
+		want []string
+	}{
+		{
+			name: "tail of log without markers",
+			log:  numberedLines(1, 10),
+			opts: Options{TailLines: 3},
+			want: []string{"line 8", "line 9", "line 10"},
+		},
+		{
+			name: "marker with context",
+			log:  append(append(numberedLines(1, 5), "[FAILED] test failed"), numberedLines(6, 10)...),
+			opts: Options{ContextLines: 2},
+			want: []string{"line 4", "line 5", "[FAILED] test failed", "line 6", "line 7"},
+		},
+		{
+			name: "separated parts",
+			log:  append(append(append([]string{"panic: boom"}, numberedLines(1, 10)...), "level=error msg=x"), numberedLines(11, 12)...),
+			opts: Options{ContextLines: 1},
+			want: []string{"panic: boom", "line 1", separator, "line 10", "level=error msg=x", "line 11"},
+		},
+		{
+			name: "size limit keeps the latest lines of a part",
+			log:  []string{"[FAIL] 1", "[FAIL] 2", "[FAIL] 3", "[FAIL] 4"},
+			opts: Options{ContextLines: 1, MaxSize: 18},
+			want: []string{separator, "[FAIL] 3", "[FAIL] 4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(strings.NewReader(strings.Join(tt.log, "\n")+"\n"), tt.opts)
+			if err != nil {
+				t.Fatalf("Extract failed: %+v", err)
+			}
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Extract() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestExtractTruncatesLongLines(t *testing.T) {
+	longLine := "level=error " + strings.Repeat("x", 3*maxLineSize)
+	log := "line 1\n" + longLine + "\nline 2\n"
+
+	got, err := Extract(strings.NewReader(log), Options{MaxSize: 4 * maxLineSize})
+	if err != nil {
+		t.Fatalf("Extract failed: %+v", err)
+	}
+
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 || lines[0] != "line 1" || lines[2] != "line 2" {
+		t.Fatalf("unexpected lines around the long line: %q", []string{lines[0], lines[len(lines)-1]})
+	}
+	if want := longLine[:maxLineSize] + truncatedLineSuffix; lines[1] != want {
+		t.Errorf("long line has %d bytes, want it truncated to %d bytes", len(lines[1]), len(want))
+	}
+}
@@ -0,0 +1,286 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+const (
+	// MaxCommentSize is the maximum size (in characters) of a GitHub comment
+	MaxCommentSize = 65536
+
+	// minFailureSize is the minimum size of a failure's details kept when the report has to be truncated
+	minFailureSize = 1000
+	// footerReserve is the space reserved for the note about omitted failures
+	footerReserve  = 500
+	truncationMark = "\n... (truncated) ...\n"
+	// maxLineSize and maxCellSize limit the size of the warning and classification lines and of table cells
+	maxLineSize = 1000
+	maxCellSize = 200
+)
+
+// Step represents the status of an openshift-ci step
+type Step struct {
+	Name     string
+	Passed   bool
+	Duration time.Duration
+	// ArtifactsURL is the URL for browsing the step's artifacts
+	ArtifactsURL string
+}
+
+// KnownIssue represents a failed test matched by a known issue
+type KnownIssue struct {
+	Test  string
+	Issue string
+	URL   string
+	Note  string
+}
+
+// Report contains data rendered into the Markdown report
+type Report struct {
+	Title string
+	// JobURL is the URL of the Prow job
+	JobURL string
+	// HTMLReportURL is the URL of the full HTML report (optional)
+	HTMLReportURL string
+	Suites        *reporters.JUnitTestSuites
+	Steps         []Step
+	KnownIssues   []KnownIssue
+	// Classification summarizes the categories of the failures (optional)
+	Classification string
+	// Warning is shown at the top of the report, e.g. if the report is incomplete (optional)
+	Warning string
+	// MaxSize limits the size of the rendered report (defaults to MaxCommentSize)
+	MaxSize int
+}
+
+// Render returns the Markdown report consisting of a summary table of the JUnit suites, statuses of the steps
+// and collapsible details of each failure. If the report doesn't fit into the MaxSize, rows of the tables that
+// don't fit are only counted (keeping space for the failures), details of the failures are shortened and
+// the failures that still don't fit are only counted
+func Render(r Report) string {
+	if r.MaxSize <= 0 {
+		r.MaxSize = MaxCommentSize
+	}
+	failures := collectFailures(r.Suites)
+	// Tables may use the space that isn't needed for the footer and at least one failure
+	tablesLimit := r.MaxSize - footerReserve
+	if len(failures) > 0 {
+		tablesLimit -= minFailureSize
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", r.Title)
+	var links []string
+	if r.JobURL != "" {
+		links = append(links, fmt.Sprintf("[Prow job](%s)", r.JobURL))
+	}
+	if r.HTMLReportURL != "" {
+		links = append(links, fmt.Sprintf("[Full report](%s)", r.HTMLReportURL))
+	}
+	if len(links) > 0 {
+		sb.WriteString(strings.Join(links, " | ") + "\n\n")
+	}
+	if r.Warning != "" {
+		fmt.Fprintf(&sb, "**Warning:** %s\n\n", escapeHTML(truncate(r.Warning, maxLineSize)))
+	}
+	if r.Classification != "" {
+		fmt.Fprintf(&sb, "**Failure classification:** %s\n\n", escapeHTML(truncate(r.Classification, maxLineSize)))
+	}
+
+	writeSummary(&sb, r.Suites, tablesLimit)
+	writeSteps(&sb, r.Steps, tablesLimit)
+	writeKnownIssues(&sb, r.KnownIssues, tablesLimit)
+
+	if len(failures) > 0 {
+		writeFailures(&sb, failures, r)
+	}
+
+	// The limit can only be exceeded by an extremely long title or links
+	if sb.Len() > r.MaxSize {
+		return strings.ToValidUTF8(sb.String()[:r.MaxSize-len(truncationMark)], "") + truncationMark
+	}
+	return sb.String()
+}
+
+func writeFailures(sb *strings.Builder, failures []failure, r Report) {
+	fmt.Fprintf(sb, "### Failures (%d)\n\n", len(failures))
+	budget := r.MaxSize - sb.Len() - footerReserve
+	maxFailureSize := budget / len(failures)
+	if maxFailureSize < minFailureSize {
+		maxFailureSize = minFailureSize
+	}
+
+	for i, f := range failures {
+		block := f.render(maxFailureSize)
+		if sb.Len()+len(block) > r.MaxSize-footerReserve {
+			fmt.Fprintf(sb, "... and %d more failures not shown due to the size limit", len(failures)-i)
+			if r.HTMLReportURL != "" {
+				fmt.Fprintf(sb, ", see the [full report](%s)", r.HTMLReportURL)
+			}
+			sb.WriteString("\n")
+			break
+		}
+		sb.WriteString(block)
+	}
+}
+
+// Helper function to write a table with the given header and rows. Rows that would make the report longer
+// than 'limit' are omitted and only counted as 'what' (e.g. "steps"). The footer row is always written
+func writeTable(sb *strings.Builder, header string, rows []string, footer string, limit int, what string) {
+	sb.WriteString(header)
+	for i, row := range rows {
+		if sb.Len()+len(row)+len(footer) > limit {
+			sb.WriteString(footer)
+			fmt.Fprintf(sb, "\n... and %d more %s not shown due to the size limit\n\n", len(rows)-i, what)
+			return
+		}
+		sb.WriteString(row)
+	}
+	sb.WriteString(footer + "\n")
+}
+
+func writeSummary(sb *strings.Builder, suites *reporters.JUnitTestSuites, limit int) {
+	var rows []string
+	var tests, failed, errors, skipped int
+	var total float64
+	for _, suite := range suites.TestSuites {
+		if len(suite.TestCases) == 0 {
+			continue
+		}
+		suiteSkipped := suite.Skipped + suite.Disabled
+		passed := suite.Tests - suite.Failures - suite.Errors - suiteSkipped
+		rows = append(rows, fmt.Sprintf("| %s | %d | %d | %d | %d | %d | %s |\n", escapeCell(truncate(suite.Name, maxCellSize)), suite.Tests, passed, suite.Failures, suite.Errors, suiteSkipped, formatSeconds(suite.Time)))
+		tests, failed, errors, skipped, total = tests+suite.Tests, failed+suite.Failures, errors+suite.Errors, skipped+suiteSkipped, total+suite.Time
+	}
+	footer := fmt.Sprintf("| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** | **%s** |\n", tests, tests-failed-errors-skipped, failed, errors, skipped, formatSeconds(total))
+
+	writeTable(sb, "| Suite | Tests | Passed | Failed | Errors | Skipped | Time |\n| --- | ---: | ---: | ---: | ---: | ---: | ---: |\n", rows, footer, limit, "suites")
+}
+
+func writeSteps(sb *strings.Builder, steps []Step, limit int) {
+	if len(steps) == 0 {
+		return
+	}
+
+	var rows []string
+	for _, s := range steps {
+		status := ":white_check_mark: passed"
+		if !s.Passed {
+			status = ":x: failed"
+		}
+		artifacts := ""
+		if s.ArtifactsURL != "" {
+			artifacts = fmt.Sprintf("[artifacts](%s)", s.ArtifactsURL)
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |\n", escapeCell(truncate(s.Name, maxCellSize)), status, s.Duration.Round(time.Second), artifacts))
+	}
+	writeTable(sb, "### Steps\n\n| Step | Status | Duration | Artifacts |\n| --- | --- | ---: | --- |\n", rows, "", limit, "steps")
+}
+
+func writeKnownIssues(sb *strings.Builder, issues []KnownIssue, limit int) {
+	if len(issues) == 0 {
+		return
+	}
+
+	var rows []string
+	for _, i := range issues {
+		issue := escapeCell(truncate(i.Issue, maxCellSize))
+		if i.URL != "" {
+			issue = fmt.Sprintf("[%s](%s)", issue, i.URL)
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s |\n", escapeCell(truncate(i.Test, maxCellSize)), issue, escapeCell(truncate(i.Note, maxCellSize))))
+	}
+	writeTable(sb, "### Known issues\n\n| Test | Issue | Note |\n| --- | --- | --- |\n", rows, "", limit, "known issues")
+}
+
+// failure represents a failed test case
+type failure struct {
+	title   string
+	details string
+}
+
+func collectFailures(suites *reporters.JUnitTestSuites) []failure {
+	var failures []failure
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			var parts []string
+			switch {
+			case tc.Failure != nil:
+				parts = append(parts, tc.Failure.Message)
+				if tc.Failure.Description != "" && tc.Failure.Description != tc.Failure.Message {
+					parts = append(parts, tc.Failure.Description)
+				}
+			case tc.Error != nil:
+				parts = append(parts, tc.Error.Message)
+				if tc.Error.Description != "" && tc.Error.Description != tc.Error.Message {
+					parts = append(parts, tc.Error.Description)
+				}
+			default:
+				continue
+			}
+			if tc.SystemErr != "" {
+				parts = append(parts, tc.SystemErr)
+			}
+			failures = append(failures, failure{title: suite.Name + " / " + tc.Name, details: strings.Join(parts, "\n\n")})
+		}
+	}
+	return failures
+}
+
+// Helper function to render the failure as a collapsible block of at most (approximately) 'maxSize' characters.
+// Details that don't fit are shortened in the middle, keeping their beginning and end
+func (f failure) render(maxSize int) string {
+	details := strings.TrimSpace(f.details)
+	if limit := maxSize - len(f.title) - 100; len(details) > limit {
+		if limit < 0 {
+			limit = 0
+		}
+		details = strings.ToValidUTF8(details[:limit/2], "") + truncationMark + strings.ToValidUTF8(details[len(details)-limit/2:], "")
+	}
+
+	fence := codeFence(details)
+	return fmt.Sprintf("<details>\n<summary>:x: %s</summary>\n\n%s\n%s\n%s\n</details>\n\n", escapeHTML(truncate(f.title, maxLineSize)), fence, details, fence)
+}
+
+// Helper function to return a code fence longer than any sequence of backticks in the content
+func codeFence(content string) string {
+	longest, current := 0, 0
+	for _, c := range content {
+		if c == '`' {
+			current++
+			if current > longest {
+				longest = current
+			}
+		} else {
+			current = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// Helper function to shorten the string to at most 'maxSize' bytes (plus the ellipsis)
+func truncate(s string, maxSize int) string {
+	if len(s) <= maxSize {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxSize], "") + "..."
+}
+
+func escapeCell(s string) string {
+	return strings.ReplaceAll(escapeHTML(s), "|", "\\|")
+}
+
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
@@ -0,0 +1,116 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// Helper function to create a suite with the given number of test cases, each failing with 'detailsSize' bytes
+// of details
+func failingSuite(name string, failures, detailsSize int) reporters.JUnitTestSuite {
+	suite := reporters.JUnitTestSuite{Name: name, Tests: failures, Failures: failures}
+	for i := 0; i < failures; i++ {
+		details := fmt.Sprintf("failure %d start ", i) + strings.Repeat("x", detailsSize) + fmt.Sprintf(" failure %d end", i)
+		suite.TestCases = append(suite.TestCases, reporters.JUnitTestCase{Name: fmt.Sprintf("test %d", i), Status: "failed", Failure: &reporters.JUnitFailure{Message: details}})
+	}
+	return suite
+}
+
+func TestRender(t *testing.T) {
+	var manySteps []Step
+	for i := 0; i < 2000; i++ {
+		manySteps = append(manySteps, Step{Name: fmt.Sprintf("step-%d", i), Passed: true, Duration: time.Minute, ArtifactsURL: "https://prow/artifacts/step"})
+	}
+	var manyIssues []KnownIssue
+	for i := 0; i < 2000; i++ {
+		manyIssues = append(manyIssues, KnownIssue{Test: fmt.Sprintf("test %d", i), Issue: "ISSUE-1", URL: "https://issues/ISSUE-1", Note: "flaky"})
+	}
+	var manySuites []reporters.JUnitTestSuite
+	for i := 0; i < 2000; i++ {
+		manySuites = append(manySuites, reporters.JUnitTestSuite{Name: fmt.Sprintf("suite %d", i), Tests: 1, TestCases: []reporters.JUnitTestCase{{Name: "passes", Status: "passed"}}})
+	}
+
+	tests := []struct {
+		name    string
+		report  Report
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "report fits",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 2, 100)}}, Steps: manySteps[:2], KnownIssues: manyIssues[:2]},
+			want:    []string{"| e2e | 2 | 0 | 2 |", "| step-1 |", "| test 1 | [ISSUE-1]", "failure 0 start", "failure 1 end"},
+			notWant: []string{"size limit", truncationMark},
+		},
+		{
+			name:    "failure details are shortened",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 3, 20000)}}},
+			want:    []string{"failure 0 start", "failure 0 end", "failure 2 start", "failure 2 end", truncationMark},
+			notWant: []string{"size limit"},
+		},
+		{
+			name:   "failures that don't fit are omitted",
+			report: Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 200, 2000)}}, HTMLReportURL: "https://report"},
+			want:   []string{"failure 0 start", "more failures not shown due to the size limit, see the [full report](https://report)"},
+		},
+		{
+			name:    "steps table is shortened",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 1, 100)}}, Steps: manySteps},
+			want:    []string{"| step-0 |", "more steps not shown due to the size limit", "failure 0 end"},
+			notWant: []string{"| step-1999 |"},
+		},
+		{
+			name:    "known issues table is shortened",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 1, 100)}}, KnownIssues: manyIssues},
+			want:    []string{"| test 0 |", "more known issues not shown due to the size limit", "failure 0 end"},
+			notWant: []string{"| test 1999 |"},
+		},
+		{
+			name:    "tables following a shortened table are shortened",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{failingSuite("e2e", 1, 100)}}, Steps: manySteps, KnownIssues: manyIssues},
+			want:    []string{"more steps not shown", "### Known issues", "2000 more known issues not shown", "failure 0 end"},
+			notWant: []string{"| test 0 |"},
+		},
+		{
+			name:    "suites table is shortened and keeps the total",
+			report:  Report{Suites: &reporters.JUnitTestSuites{TestSuites: manySuites}},
+			want:    []string{"| suite 0 |", "| **Total** | **2000** | **2000** |", "more suites not shown due to the size limit"},
+			notWant: []string{"| suite 1999 |"},
+		},
+		{
+			name:    "long warning is shortened",
+			report:  Report{Suites: &reporters.JUnitTestSuites{}, Warning: strings.Repeat("skipped artifact ", 10000)},
+			want:    []string{"**Warning:** skipped artifact", "..."},
+			notWant: []string{strings.Repeat("skipped artifact ", 100)},
+		},
+		{
+			name:   "long title is cut",
+			report: Report{Suites: &reporters.JUnitTestSuites{}, Title: strings.Repeat("title ", 20000)},
+			want:   []string{truncationMark},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.report.MaxSize = 50000
+			got := Render(tt.report)
+			if len(got) > tt.report.MaxSize {
+				t.Errorf("report has %d bytes, want at most %d", len(got), tt.report.MaxSize)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("report doesn't contain %q", s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("report contains %q", s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package prow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// CachedSource is an ArtifactSource that caches content of objects read from another
+// ArtifactSource in a local directory. Objects are cached under a key composed of the bucket name,
+// object name and object generation. Generations are remembered from List, objects read without being
+// listed first (e.g. "latest-build.txt") are listed to get their generation. Objects without a generation
+// (e.g. from a LocalSource) are not cached. Least recently used objects are evicted once the size
+// of the cache directory exceeds the configured limit
+type CachedSource struct {
+	source  ArtifactSource
+	dir     string
+	bucket  string
+	maxSize int64
+
+	mu sync.Mutex
+	// generations of listed objects, zero for objects without a generation
+	generations map[string]int64
+	// entries of the cache directory and their total size, loaded from the directory on first use
+	entries   map[string]*cacheEntry
+	totalSize int64
+}
+
+type cacheEntry struct {
+	size     int64
+	lastUsed time.Time
+}
+
+// cacheTempFilePattern is the pattern of temporary files objects are written to before being
+// moved to the cache, such files may belong to other processes sharing the cache directory
+const cacheTempFilePattern = ".cache-*"
+
+// NewCachedSource returns an ArtifactSource caching objects of the given bucket read from 'source'
+// in the directory 'dir'. If 'maxSize' (in bytes) is not positive, the size of the cache is not limited
+func NewCachedSource(source ArtifactSource, dir, bucket string, maxSize int64) *CachedSource {
+	return &CachedSource{
+		source:      source,
+		dir:         dir,
+		bucket:      bucket,
+		maxSize:     maxSize,
+		generations: map[string]int64{},
+	}
+}
+
+// List returns attributes of all objects whose name starts with the given prefix
+// and remembers their generations for looking them up in the cache
+func (s *CachedSource) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	objects, err := s.source.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range objects {
+		s.generations[o.Name] = o.Generation
+	}
+
+	return objects, nil
+}
+
+// ListDirectories returns prefixes of all "directories" directly within the given prefix
+func (s *CachedSource) ListDirectories(ctx context.Context, prefix string) ([]string, error) {
+	return s.source.ListDirectories(ctx, prefix)
+}
+
+// NewReader opens the object with the given name for reading, either from the cache,
+// or from the underlying source (storing the object in the cache)
+func (s *CachedSource) NewReader(ctx context.Context, name string) (io.ReadCloser, error) {
+	generation, err := s.generation(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if generation == 0 {
+		return s.source.NewReader(ctx, name)
+	}
+
+	path := s.objectPath(name, generation)
+	if f, err := os.Open(path); err == nil {
+		// Mark the object as recently used
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		if info, err := f.Stat(); err == nil {
+			s.track(path, info.Size(), now)
+		}
+		return f, nil
+	}
+
+	size, err := s.store(ctx, name, path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cached object %s: %+v", name, err)
+	}
+	s.track(path, size, time.Now())
+	s.evict(path)
+
+	return f, nil
+}
+
+// Helper function to get the generation of the object with the given name. Objects that weren't listed
+// before are listed, zero is returned if the object doesn't exist or it doesn't have a generation
+func (s *CachedSource) generation(ctx context.Context, name string) (int64, error) {
+	s.mu.Lock()
+	generation, ok := s.generations[name]
+	s.mu.Unlock()
+	if ok {
+		return generation, nil
+	}
+
+	objects, err := s.List(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get generation of %s: %w", name, err)
+	}
+	for _, o := range objects {
+		if o.Name == name {
+			return o.Generation, nil
+		}
+	}
+	return 0, nil
+}
+
+// Helper function to get the path of the cached object with the given name and generation
+func (s *CachedSource) objectPath(name string, generation int64) string {
+	sum := sha256.Sum256([]byte(s.bucket + "/" + name + "#" + strconv.FormatInt(generation, 10)))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(s.dir, s.bucket, key[:2], key)
+}
+
+// Helper function to read the object from the underlying source and store it in the cache.
+// Returns the size of the stored object
+func (s *CachedSource) store(ctx context.Context, name, path string) (int64, error) {
+	rc, err := s.source.NewReader(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create cache directory for %s: %+v", name, err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), cacheTempFilePattern)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create cache file for %s: %+v", name, err)
+	}
+	defer os.Remove(f.Name())
+
+	size, err := io.Copy(f, rc)
+	if err != nil {
+		f.Close()
+		return 0, fmt.Errorf("cannot read from storage reader: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, fmt.Errorf("failed to write cache file for %s: %+v", name, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to store %s in the cache: %+v", name, err)
+	}
+	return size, nil
+}
+
+// Helper function to record the use of the cached file with the given path in the in-memory index
+func (s *CachedSource) track(path string, size int64, lastUsed time.Time) {
+	if s.maxSize <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadEntries()
+	if e, ok := s.entries[path]; ok {
+		s.totalSize += size - e.size
+		e.size, e.lastUsed = size, lastUsed
+		return
+	}
+	s.entries[path] = &cacheEntry{size: size, lastUsed: lastUsed}
+	s.totalSize += size
+}
+
+// Helper function to index files already present in the cache directory (e.g. from previous runs).
+// Temporary files are skipped. Has to be called with the mutex held
+func (s *CachedSource) loadEntries() {
+	if s.entries != nil {
+		return
+	}
+
+	s.entries = map[string]*cacheEntry{}
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		if matched, _ := filepath.Match(cacheTempFilePattern, d.Name()); matched {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		s.entries[path] = &cacheEntry{size: info.Size(), lastUsed: info.ModTime()}
+		s.totalSize += info.Size()
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		klog.Warningf("failed to read the cache directory %s: %+v", s.dir, err)
+	}
+}
+
+// Helper function to remove least recently used objects from the cache directory
+// until its size doesn't exceed the limit. The object with the path 'keep' (the one just stored) is never removed
+func (s *CachedSource) evict(keep string) {
+	if s.maxSize <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.totalSize <= s.maxSize {
+		return
+	}
+
+	paths := make([]string, 0, len(s.entries))
+	for path := range s.entries {
+		if path != keep {
+			paths = append(paths, path)
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool { return s.entries[paths[i]].lastUsed.Before(s.entries[paths[j]].lastUsed) })
+
+	for _, path := range paths {
+		if s.totalSize <= s.maxSize {
+			break
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			klog.Warningf("failed to evict %s from the cache: %+v", path, err)
+			continue
+		}
+		s.totalSize -= s.entries[path].size
+		delete(s.entries, path)
+	}
+}
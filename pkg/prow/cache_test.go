@@ -0,0 +1,162 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// countingSource is an ArtifactSource assigning the generations to the objects listed from the wrapped source
+// (objects without a generation are listed without it) and counting reads of objects
+type countingSource struct {
+	ArtifactSource
+	generations map[string]int64
+
+	mu    sync.Mutex
+	reads map[string]int
+}
+
+func newCountingSource(source ArtifactSource, generations map[string]int64) *countingSource {
+	return &countingSource{ArtifactSource: source, generations: generations, reads: map[string]int{}}
+}
+
+func (s *countingSource) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	objects, err := s.ArtifactSource.List(ctx, prefix)
+	for i := range objects {
+		objects[i].Generation = s.generations[objects[i].Name]
+	}
+	return objects, err
+}
+
+func (s *countingSource) NewReader(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	s.reads[name]++
+	s.mu.Unlock()
+	return s.ArtifactSource.NewReader(ctx, name)
+}
+
+// Helper function to read the objects with the given names from the source, checking their content
+func readObjects(t *testing.T, s ArtifactSource, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if got, want := readAll(t, s, name), readAll(t, NewLocalSource(testBucket), name); got != want {
+			t.Errorf("content of %s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// Helper function to return the total size of the objects stored in the cache directory (without temporary files)
+func cacheSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		if matched, _ := filepath.Match(cacheTempFilePattern, d.Name()); matched {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk the cache directory: %+v", err)
+	}
+	return size
+}
+
+var (
+	// Objects of the generic job and their sizes
+	jobLog      = genericJobPrefix + "/build-log.txt"                 // 16 bytes
+	rootLog     = genericJobPrefix + "/artifacts/build-log.txt"       // 17 bytes
+	testsLog    = genericJobPrefix + "/artifacts/tests/build-log.txt" // 10 bytes
+	testsJUnit  = genericJobPrefix + "/artifacts/tests/junit.xml"     // 26 bytes
+	generations = map[string]int64{jobLog: 1, rootLog: 2, testsLog: 3, testsJUnit: 4}
+)
+
+func TestCachedSource(t *testing.T) {
+	ctx := context.Background()
+	source := newCountingSource(NewLocalSource(testBucket), map[string]int64{jobLog: 1, testsJUnit: 4})
+	s := NewCachedSource(source, t.TempDir(), OpenshiftCI.BucketName, 0)
+
+	if _, err := s.List(ctx, genericJobPrefix+"/artifacts/tests/"); err != nil {
+		t.Fatalf("List failed: %+v", err)
+	}
+	for i := 0; i < 2; i++ {
+		// The job log isn't listed before reading, so its generation has to be looked up
+		readObjects(t, s, testsJUnit, testsLog, jobLog)
+	}
+	if _, err := s.NewReader(ctx, genericJobPrefix+"/missing.txt"); !errors.Is(err, ErrObjectNotExist) {
+		t.Errorf("NewReader() of a missing object error = %v, want %v", err, ErrObjectNotExist)
+	}
+
+	// Objects with a generation are read from the cache once they are stored
+	for name, want := range map[string]int{testsJUnit: 1, jobLog: 1, testsLog: 2} {
+		if got := source.reads[name]; got != want {
+			t.Errorf("%s read %d times from the source, want %d", name, got, want)
+		}
+	}
+
+	// A new generation of an object isn't read from the cache
+	source.generations[jobLog] = 5
+	s = NewCachedSource(source, s.dir, OpenshiftCI.BucketName, 0)
+	readObjects(t, s, jobLog)
+	if got := source.reads[jobLog]; got != 2 {
+		t.Errorf("new generation of %s read %d times from the source, want twice", jobLog, got)
+	}
+}
+
+func TestCachedSourceEviction(t *testing.T) {
+	source := newCountingSource(NewLocalSource(testBucket), generations)
+	s := NewCachedSource(source, t.TempDir(), OpenshiftCI.BucketName, 30)
+
+	readObjects(t, s, testsJUnit, testsLog)
+	// The JUnit report is evicted to make space for the log
+	if size := cacheSize(t, s.dir); size != 10 {
+		t.Errorf("cache size = %d, want 10", size)
+	}
+	readObjects(t, s, testsLog, jobLog, testsJUnit)
+	for name, want := range map[string]int{testsJUnit: 2, testsLog: 1, jobLog: 1} {
+		if got := source.reads[name]; got != want {
+			t.Errorf("%s read %d times from the source, want %d", name, got, want)
+		}
+	}
+	// An object bigger than the limit is kept in the cache until another object is stored
+	if size := cacheSize(t, s.dir); size != 26 || s.totalSize != 26 {
+		t.Errorf("cache size = %d (tracked %d), want 26", size, s.totalSize)
+	}
+}
+
+func TestCachedSourceLoadsEntries(t *testing.T) {
+	dir := t.TempDir()
+	source := newCountingSource(NewLocalSource(testBucket), generations)
+	readObjects(t, NewCachedSource(source, dir, OpenshiftCI.BucketName, 0), testsJUnit, testsLog, rootLog)
+	// Temporary files of other processes are neither counted nor removed
+	tempFile := filepath.Join(dir, ".cache-123")
+	if err := os.WriteFile(tempFile, make([]byte, 100), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Objects cached by the previous run count towards the limit and they are evicted first
+	s := NewCachedSource(source, dir, OpenshiftCI.BucketName, 30)
+	readObjects(t, s, jobLog)
+	if size := cacheSize(t, dir); size > 30 || size != s.totalSize {
+		t.Errorf("cache size = %d (tracked %d), want at most 30", size, s.totalSize)
+	}
+	if _, err := os.Stat(tempFile); err != nil {
+		t.Errorf("temporary file was removed: %+v", err)
+	}
+	readObjects(t, s, jobLog)
+	if got := source.reads[jobLog]; got != 1 {
+		t.Errorf("%s read %d times from the source, want once", jobLog, got)
+	}
+}
@@ -0,0 +1,213 @@
+package prow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// downloadManifestFileName is the name of the file storing generations
+// of objects downloaded into the target directory
+const downloadManifestFileName = ".download-manifest.json"
+
+// DownloadResult summarizes the result of downloading job's artifacts
+type DownloadResult struct {
+	Downloaded int
+	Skipped    int
+}
+
+// Download copies all artifacts of the Prow job into the given directory, keeping their layout
+// relative to the job's root directory. Only artifacts matching the include rules (all artifacts if there are none)
+// and not matching the exclude rules of the ScannerConfig are downloaded. Artifacts already present in the directory
+// with the same generation (or the same size, if their generation isn't known) are skipped, so repeated downloads
+// only fetch new or changed artifacts
+func (as *ArtifactScanner) Download(ctx context.Context, dir string) (*DownloadResult, error) {
+	jobDirectoryPrefix, err := as.getJobDirectoryPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobDirectoryPrefix += "/"
+
+	// Steps of the artifacts are only needed (and the job target only resolved) if the filter matches steps
+	var artifactDirectoryPrefix string
+	if as.filter.matchesSteps {
+		jobTarget, pjURL, err := as.determineJobDetails(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine job details: %+v", err)
+		}
+		if artifactDirectoryPrefix, err = getArtifactsDirectoryPrefix(as, pjURL, jobTarget); err != nil {
+			return nil, fmt.Errorf("failed to get artifact directory prefix: %+v", err)
+		}
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+	objects, err := as.list(listCtx, jobDirectoryPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list storage objects: %+v", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory '%s': %+v", dir, err)
+	}
+	manifest, err := readDownloadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DownloadResult{}
+	var toDownload []ObjectAttrs
+	for _, o := range objects {
+		if !as.filter.matches(artifactAttrs{path: o.Name, name: path.Base(o.Name), step: artifactStep(o.Name, artifactDirectoryPrefix), size: o.Size}, true) {
+			continue
+		}
+		relativeName := strings.TrimPrefix(o.Name, jobDirectoryPrefix)
+		if isDownloaded(dir, relativeName, o, manifest[relativeName]) {
+			result.Skipped++
+			continue
+		}
+		toDownload = append(toDownload, o)
+	}
+
+	var errs []error
+	mu := sync.Mutex{}
+	forEachConcurrently(toDownload, as.config.Concurrency, func(o ObjectAttrs) {
+		relativeName := strings.TrimPrefix(o.Name, jobDirectoryPrefix)
+		err := as.downloadObject(ctx, o.Name, filepath.Join(dir, filepath.FromSlash(filepath.Clean("/"+relativeName))))
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		manifest.set(relativeName, o.Generation)
+		result.Downloaded++
+	})
+
+	if err := manifest.write(dir); err != nil {
+		return nil, err
+	}
+	klog.Infof("downloaded %d and skipped %d artifacts of the job %s", result.Downloaded, result.Skipped, jobDirectoryPrefix)
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("failed to download %d artifacts: %w", len(errs), errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// Helper function to download the object with the given name into the given path
+func (as *ArtifactScanner) downloadObject(ctx context.Context, name, path string) error {
+	return retry(ctx, as.config.Retry, "downloading "+name, func() error {
+		return as.downloadObjectOnce(ctx, name, path)
+	})
+}
+
+// Helper function to make a single attempt to download an object, limited by ScannerConfig.ObjectTimeout
+func (as *ArtifactScanner) downloadObjectOnce(ctx context.Context, name, path string) error {
+	ctx, cancel := context.WithTimeout(ctx, as.config.ObjectTimeout)
+	defer cancel()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %+v", name, err)
+	}
+
+	rc, err := as.source.NewReader(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	// Write into a temporary file first, so that interrupted downloads don't leave incomplete files behind
+	f, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file for %s: %+v", name, err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %+v", path, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to move downloaded %s to %s: %+v", name, path, err)
+	}
+
+	return nil
+}
+
+// Helper function to check if the object was already downloaded into the directory. The object is considered
+// downloaded if the generation of the downloaded object is the same, or if the generation isn't known
+// (e.g. for objects from a LocalSource) and the size of the file is the same
+func isDownloaded(dir, relativeName string, o ObjectAttrs, downloadedGeneration int64) bool {
+	info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(filepath.Clean("/"+relativeName))))
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	// Sizes of different generations may be equal, so the size is only compared if the generation isn't known
+	if downloadedGeneration != 0 {
+		return downloadedGeneration == o.Generation
+	}
+	return info.Size() == o.Size
+}
+
+// downloadManifest maps names of downloaded objects (relative to the job's root directory) to their generation
+type downloadManifest map[string]int64
+
+func readDownloadManifest(dir string) (downloadManifest, error) {
+	manifest := downloadManifest{}
+
+	data, err := os.ReadFile(filepath.Join(dir, downloadManifestFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read download manifest: %+v", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse download manifest: %+v", err)
+	}
+	return manifest, nil
+}
+
+func (m downloadManifest) set(name string, generation int64) {
+	if generation != 0 {
+		m[name] = generation
+	}
+}
+
+func (m downloadManifest) write(dir string) error {
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal download manifest: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, downloadManifestFileName), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write download manifest: %+v", err)
+	}
+	return nil
+}
+
+// Helper function to get the name of the openshift-ci step the artifact belongs to,
+// or an empty string if the artifact isn't stored within the artifact directory
+func artifactStep(name, artifactDirectoryPrefix string) string {
+	if artifactDirectoryPrefix == "" || !strings.HasPrefix(name, artifactDirectoryPrefix) {
+		return ""
+	}
+	step, err := getParentStepName(name, artifactDirectoryPrefix)
+	if err != nil {
+		return ""
+	}
+	return step
+}
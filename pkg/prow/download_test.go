@@ -0,0 +1,105 @@
+package prow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       ScannerConfig
+		wantFiles []string
+	}{
+		{
+			name:      "all artifacts",
+			wantFiles: []string{"build-log.txt", "artifacts/build-log.txt", "artifacts/tests/build-log.txt", "artifacts/tests/junit.xml"},
+		},
+		{
+			name:      "include and exclude rules",
+			cfg:       ScannerConfig{IncludeRules: []string{"path=**/artifacts/**"}, ExcludeRules: []string{"name=*.xml"}},
+			wantFiles: []string{"artifacts/build-log.txt", "artifacts/tests/build-log.txt"},
+		},
+		{
+			name:      "step rules",
+			cfg:       ScannerConfig{IncludeRules: []string{"step=tests"}},
+			wantFiles: []string{"artifacts/tests/build-log.txt", "artifacts/tests/junit.xml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newCountingSource(NewLocalSource(testBucket), nil)
+			tt.cfg.ArtifactSource = source
+			tt.cfg.ProwJobURL = OpenshiftCI.ProwJobURL(genericJobPrefix)
+			scanner, err := NewArtifactScanner(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create the scanner: %+v", err)
+			}
+
+			dir := t.TempDir()
+			result, err := scanner.Download(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("Download failed: %+v", err)
+			}
+			if *result != (DownloadResult{Downloaded: len(tt.wantFiles)}) {
+				t.Errorf("result = %+v, want %d downloaded artifacts", *result, len(tt.wantFiles))
+			}
+			for _, name := range tt.wantFiles {
+				got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+				if err != nil {
+					t.Fatalf("failed to read downloaded %s: %+v", name, err)
+				}
+				if want := readAll(t, source.ArtifactSource, genericJobPrefix+"/"+name); string(got) != want {
+					t.Errorf("content of %s = %q, want %q", name, got, want)
+				}
+			}
+
+			// Artifacts that are already downloaded are skipped
+			result, err = scanner.Download(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("repeated Download failed: %+v", err)
+			}
+			if *result != (DownloadResult{Skipped: len(tt.wantFiles)}) {
+				t.Errorf("result of repeated download = %+v, want %d skipped artifacts", *result, len(tt.wantFiles))
+			}
+			for _, name := range tt.wantFiles {
+				if reads := source.reads[genericJobPrefix+"/"+name]; reads != 1 {
+					t.Errorf("%s read %d times, want once", name, reads)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadComparesGenerations(t *testing.T) {
+	source := newCountingSource(NewLocalSource(testBucket), map[string]int64{jobLog: 1, rootLog: 2, testsLog: 3, testsJUnit: 4})
+	scanner, err := NewArtifactScanner(ScannerConfig{ArtifactSource: source, ProwJobURL: OpenshiftCI.ProwJobURL(genericJobPrefix)})
+	if err != nil {
+		t.Fatalf("failed to create the scanner: %+v", err)
+	}
+	dir := t.TempDir()
+	if _, err := scanner.Download(context.Background(), dir); err != nil {
+		t.Fatalf("Download failed: %+v", err)
+	}
+
+	// A new generation of the same size is downloaded, a changed file of a known generation isn't
+	source.generations[jobLog] = 5
+	if err := os.WriteFile(filepath.Join(dir, "artifacts", "build-log.txt"), []byte("modified"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	result, err := scanner.Download(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("repeated Download failed: %+v", err)
+	}
+	if *result != (DownloadResult{Downloaded: 1, Skipped: 3}) {
+		t.Errorf("result of repeated download = %+v, want 1 downloaded and 3 skipped artifacts", *result)
+	}
+	for name, want := range map[string]int{jobLog: 2, rootLog: 1, testsLog: 1, testsJUnit: 1} {
+		if got := source.reads[name]; got != want {
+			t.Errorf("%s read %d times, want %d", name, got, want)
+		}
+	}
+}
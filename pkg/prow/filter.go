@@ -0,0 +1,230 @@
+package prow
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Filter rules select artifacts by their attributes. A rule has the form "<field><operator><value>":
+//
+//	path=~<regex>  full object name matches the regular expression
+//	path=<glob>    full object name matches the glob pattern
+//	name=~<regex>  file name (the last element of the object name) matches the regular expression
+//	name=<glob>    file name matches the glob pattern
+//	step=~<regex>  name of the openshift-ci step matches the regular expression
+//	step=<glob>    name of the openshift-ci step matches the glob pattern
+//	size><size>    object is bigger than the given size (also "size>=", "size<" and "size<=")
+//
+// Glob patterns are matched against the whole value, "*" matches any sequence of characters except "/",
+// "**" matches any sequence of characters and "?" matches a single character except "/".
+// Sizes are in bytes, optionally followed by one of the suffixes "Ki", "Mi" or "Gi".
+// Step rules never match artifacts that don't belong to any step, i.e. artifacts stored outside
+// the artifact directory or directly within it (the step "/").
+// Plain regular expressions (ScannerConfig.FileNameFilter) are not rules, they are always matched
+// against the full object name
+var filterRuleRegexp = regexp.MustCompile(`^(path|name|step|size)(=~|>=|<=|=|>|<)(.*)$`)
+
+var sizeSuffixes = map[string]int64{"Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30}
+
+// artifactAttrs represents the attributes of an artifact that can be matched by filter rules
+type artifactAttrs struct {
+	path string
+	name string
+	// step is empty (or "/" for files stored directly within the artifact directory)
+	// if the artifact doesn't belong to any openshift-ci step
+	step string
+	size int64
+}
+
+// filterRule is a compiled filter rule
+type filterRule func(a artifactAttrs) bool
+
+// artifactFilter selects artifacts matching any of the include rules and none of the exclude rules
+type artifactFilter struct {
+	include []filterRule
+	exclude []filterRule
+	// matchesSteps is set if any of the rules matches the step of the artifact
+	matchesSteps bool
+}
+
+// Helper function to compile the include/exclude rules of the ScannerConfig
+func compileArtifactFilter(cfg ScannerConfig) (artifactFilter, error) {
+	var f artifactFilter
+	var err error
+
+	if f.include, err = compilePathRegexps(cfg.FileNameFilter); err != nil {
+		return artifactFilter{}, fmt.Errorf("invalid file name filter: %+v", err)
+	}
+	if f.exclude, err = compilePathRegexps(cfg.FileNameExcludeFilter); err != nil {
+		return artifactFilter{}, fmt.Errorf("invalid file name exclude filter: %+v", err)
+	}
+	includeRules, err := compileFilterRules(cfg.IncludeRules)
+	if err != nil {
+		return artifactFilter{}, fmt.Errorf("invalid include rule: %+v", err)
+	}
+	excludeRules, err := compileFilterRules(cfg.ExcludeRules)
+	if err != nil {
+		return artifactFilter{}, fmt.Errorf("invalid exclude rule: %+v", err)
+	}
+	f.include, f.exclude = append(f.include, includeRules...), append(f.exclude, excludeRules...)
+	for _, rule := range append(append([]string{}, cfg.IncludeRules...), cfg.ExcludeRules...) {
+		if m := filterRuleRegexp.FindStringSubmatch(rule); m != nil && m[1] == "step" {
+			f.matchesSteps = true
+		}
+	}
+	f.matchesSteps = f.matchesSteps || len(cfg.StepsToSkip) > 0
+	for _, step := range cfg.StepsToSkip {
+		step := step
+		f.exclude = append(f.exclude, func(a artifactAttrs) bool { return a.step == step })
+	}
+
+	return f, nil
+}
+
+// Helper function to check whether the artifact matches any of the include rules
+// (or whether there are no include rules at all, if 'includeAllByDefault' is set)
+// and none of the exclude rules
+func (f artifactFilter) matches(a artifactAttrs, includeAllByDefault bool) bool {
+	included := includeAllByDefault && len(f.include) == 0
+	for _, r := range f.include {
+		if r(a) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, r := range f.exclude {
+		if r(a) {
+			return false
+		}
+	}
+	return true
+}
+
+// Helper function to compile regular expressions matched against the full object name
+func compilePathRegexps(regexps []string) ([]filterRule, error) {
+	compiled := make([]filterRule, 0, len(regexps))
+	for _, r := range regexps {
+		re, err := regexp.Compile(r)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a valid regular expression: %+v", r, err)
+		}
+		compiled = append(compiled, func(a artifactAttrs) bool { return re.MatchString(a.path) })
+	}
+	return compiled, nil
+}
+
+func compileFilterRules(rules []string) ([]filterRule, error) {
+	compiled := make([]filterRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := compileFilterRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled, nil
+}
+
+func compileFilterRule(rule string) (filterRule, error) {
+	m := filterRuleRegexp.FindStringSubmatch(rule)
+	if m == nil {
+		return nil, fmt.Errorf("%q is not a valid rule, expected \"<field><operator><value>\" with one of the fields path, name, step or size", rule)
+	}
+	field, operator, value := m[1], m[2], m[3]
+
+	if field == "size" {
+		if operator == "=~" || operator == "=" {
+			return nil, fmt.Errorf("%q: size can only be compared using <, <=, > or >=", rule)
+		}
+		size, err := parseSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %+v", rule, err)
+		}
+		compare := map[string]func(int64) bool{
+			">":  func(s int64) bool { return s > size },
+			">=": func(s int64) bool { return s >= size },
+			"<":  func(s int64) bool { return s < size },
+			"<=": func(s int64) bool { return s <= size },
+		}[operator]
+		return func(a artifactAttrs) bool { return compare(a.size) }, nil
+	}
+
+	var re *regexp.Regexp
+	var err error
+	switch operator {
+	case "=~":
+		re, err = regexp.Compile(value)
+	case "=":
+		re, err = regexp.Compile(globToRegexp(value))
+	default:
+		return nil, fmt.Errorf("%q: %s can only be matched using = (glob) or =~ (regular expression)", rule, field)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid pattern: %+v", rule, err)
+	}
+
+	attr := map[string]func(a artifactAttrs) string{
+		"path": func(a artifactAttrs) string { return a.path },
+		"name": func(a artifactAttrs) string { return a.name },
+		"step": func(a artifactAttrs) string {
+			if a.step == "/" {
+				return ""
+			}
+			return a.step
+		},
+	}[field]
+	return func(a artifactAttrs) bool {
+		v := attr(a)
+		return v != "" && re.MatchString(v)
+	}, nil
+}
+
+// Helper function to convert a glob pattern to an anchored regular expression
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for rest := glob; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "**"):
+			sb.WriteString(".*")
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "*"):
+			sb.WriteString("[^/]*")
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "?"):
+			sb.WriteString("[^/]")
+			rest = rest[1:]
+		default:
+			i := strings.IndexAny(rest, "*?")
+			if i < 0 {
+				i = len(rest)
+			}
+			sb.WriteString(regexp.QuoteMeta(rest[:i]))
+			rest = rest[i:]
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// Helper function to parse a size in bytes, optionally followed by one of the sizeSuffixes
+func parseSize(s string) (int64, error) {
+	number, multiplier := s, int64(1)
+	for suffix, m := range sizeSuffixes {
+		if strings.HasSuffix(s, suffix) {
+			number, multiplier = strings.TrimSuffix(s, suffix), m
+			break
+		}
+	}
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%q is not a valid size", s)
+	}
+	return size * multiplier, nil
+}
@@ -0,0 +1,143 @@
+package prow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterRules(t *testing.T) {
+	junit := artifactAttrs{path: "logs/job/1/artifacts/e2e/redhat-appstudio-e2e/artifacts/junit.xml", name: "junit.xml", step: "redhat-appstudio-e2e", size: 2048}
+
+	tests := []struct {
+		rule string
+		want bool
+	}{
+		{rule: "path=**/artifacts/*.xml", want: true},
+		{rule: "path=logs/*/artifacts/**", want: false},
+		{rule: "path=logs/job/?/**", want: true},
+		{rule: "path=~/redhat-appstudio-e2e/", want: true},
+		{rule: "name=*.xml", want: true},
+		{rule: "name=junit.???", want: true},
+		{rule: "name=junit", want: false},
+		{rule: "name=~^junit", want: true},
+		{rule: "name=~^e2e", want: false},
+		{rule: "step=redhat-appstudio-*", want: true},
+		{rule: "step=gather-*", want: false},
+		{rule: "step=~e2e$", want: true},
+		{rule: "size>2Ki", want: false},
+		{rule: "size>=2Ki", want: true},
+		{rule: "size<2049", want: true},
+		{rule: "size<=2047", want: false},
+		{rule: "size>1Mi", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := compileFilterRule(tt.rule)
+			if err != nil {
+				t.Fatalf("failed to compile the rule: %+v", err)
+			}
+			if got := rule(junit); got != tt.want {
+				t.Errorf("rule %q matches %+v = %v, want %v", tt.rule, junit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidFilterRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr string
+	}{
+		{rule: "size=1Ki", wantErr: "size can only be compared"},
+		{rule: "size>1Ti", wantErr: "not a valid size"},
+		{rule: "size<-1", wantErr: "not a valid size"},
+		{rule: "name>1", wantErr: "can only be matched using"},
+		{rule: "step=~(", wantErr: "not a valid pattern"},
+		{rule: `junit\.xml$`, wantErr: "not a valid rule"},
+		{rule: "file=junit.xml", wantErr: "not a valid rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			if _, err := compileFilterRule(tt.rule); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStepRulesDontMatchArtifactsWithoutStep(t *testing.T) {
+	for _, step := range []string{"", "/"} {
+		a := artifactAttrs{path: "logs/job/1/artifacts/build-log.txt", name: "build-log.txt", step: step}
+		for _, rule := range []string{"step=**", "step=~.*"} {
+			r, err := compileFilterRule(rule)
+			if err != nil {
+				t.Fatalf("failed to compile the rule: %+v", err)
+			}
+			if r(a) {
+				t.Errorf("rule %q matches an artifact of the step %q", rule, step)
+			}
+		}
+	}
+}
+
+func TestArtifactFilter(t *testing.T) {
+	buildLog := artifactAttrs{path: "logs/job/1/artifacts/e2e/gather-extra/build-log.txt", name: "build-log.txt", step: "gather-extra", size: 100}
+	junit := artifactAttrs{path: "logs/job/1/artifacts/e2e/redhat-appstudio-e2e/artifacts/junit.xml", name: "junit.xml", step: "redhat-appstudio-e2e", size: 2048}
+	rootLog := artifactAttrs{path: "logs/job/1/artifacts/build-log.txt", name: "build-log.txt", step: "/", size: 100}
+
+	tests := []struct {
+		name                string
+		cfg                 ScannerConfig
+		includeAllByDefault bool
+		want                []bool
+	}{
+		{name: "no rules", want: []bool{false, false, false}},
+		{name: "no rules including all by default", includeAllByDefault: true, want: []bool{true, true, true}},
+		{name: "file name filter", cfg: ScannerConfig{FileNameFilter: []string{`build-log\.txt$`}}, want: []bool{true, false, true}},
+		{name: "file name exclude filter", cfg: ScannerConfig{FileNameExcludeFilter: []string{`/e2e/`}}, includeAllByDefault: true, want: []bool{false, false, true}},
+		// Regular expressions looking like rules are still matched against the full object name
+		{name: "file name filter looking like a rule", cfg: ScannerConfig{FileNameFilter: []string{"name=junit.xml", "size>1"}}, want: []bool{false, false, false}},
+		{name: "include rules", cfg: ScannerConfig{IncludeRules: []string{"name=*.xml", "step=gather-*"}}, want: []bool{true, true, false}},
+		{name: "file name filter and include rules", cfg: ScannerConfig{FileNameFilter: []string{`build-log\.txt$`}, IncludeRules: []string{"name=*.xml"}}, want: []bool{true, true, true}},
+		{name: "exclude rules", cfg: ScannerConfig{ExcludeRules: []string{"size>1Ki"}}, includeAllByDefault: true, want: []bool{true, false, true}},
+		{name: "step exclude rules", cfg: ScannerConfig{ExcludeRules: []string{"step=**"}}, includeAllByDefault: true, want: []bool{false, false, true}},
+		{name: "skipped steps", cfg: ScannerConfig{IncludeRules: []string{"path=**"}, StepsToSkip: []string{"gather-extra"}}, want: []bool{false, true, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := compileArtifactFilter(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to compile the filter: %+v", err)
+			}
+			for i, a := range []artifactAttrs{buildLog, junit, rootLog} {
+				if got := f.matches(a, tt.includeAllByDefault); got != tt.want[i] {
+					t.Errorf("filter matches %s = %v, want %v", a.path, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidArtifactFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ScannerConfig
+		wantErr string
+	}{
+		{name: "file name filter", cfg: ScannerConfig{FileNameFilter: []string{"junit("}}, wantErr: "invalid file name filter"},
+		{name: "file name exclude filter", cfg: ScannerConfig{FileNameExcludeFilter: []string{"junit("}}, wantErr: "invalid file name exclude filter"},
+		{name: "include rule", cfg: ScannerConfig{IncludeRules: []string{`junit\.xml`}}, wantErr: "invalid include rule"},
+		{name: "exclude rule", cfg: ScannerConfig{ExcludeRules: []string{"size>big"}}, wantErr: "invalid exclude rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := compileArtifactFilter(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const latestBuildFileName = "latest-build.txt"
+
+// ListJobRuns returns metadata of the last 'count' runs of the Prow job with the given name,
+// starting with the latest one. Runs of periodic and postsubmit jobs are looked up
+// in the "logs/<job-name>/" directory, runs of presubmit jobs (prefixed with "pull-")
+// are resolved via the "pr-logs/directory/<job-name>/" directory.
+// The returned job runs don't contain any steps nor pod info
+func (as *ArtifactScanner) ListJobRuns(ctx context.Context, jobName string, count int) ([]JobRun, error) {
+	if jobName == "" {
+		return nil, fmt.Errorf("job name has to be specified")
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("number of job runs has to be positive, got %d", count)
+	}
+
+	jobHistoryPrefix := getJobHistoryPrefix(jobName)
+	buildIDs, err := as.getLatestBuildIDs(ctx, jobHistoryPrefix, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list builds of the job %s: %+v", jobName, err)
+	}
+
+	jobRuns := make([]JobRun, 0, len(buildIDs))
+	for _, buildID := range buildIDs {
+		jobRun, err := as.readBuild(ctx, jobHistoryPrefix, buildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata of the build %s of the job %s: %+v", buildID, jobName, err)
+		}
+		jobRun.JobName = jobName
+		jobRuns = append(jobRuns, *jobRun)
+	}
+
+	return jobRuns, nil
+}
+
+// Helper function to get IDs of the latest 'count' builds of the job, sorted from the latest to the oldest one
+func (as *ArtifactScanner) getLatestBuildIDs(ctx context.Context, jobHistoryPrefix string, count int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, listTimeout)
+	defer cancel()
+
+	if count == 1 {
+		data, err := as.readObject(ctx, jobHistoryPrefix+latestBuildFileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest build: %+v", err)
+		}
+		return []string{strings.TrimSpace(string(data))}, nil
+	}
+
+	buildIDs, err := as.listBuildIDs(ctx, jobHistoryPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(buildIDs) > count {
+		buildIDs = buildIDs[:count]
+	}
+	return buildIDs, nil
+}
+
+// Helper function to read metadata of the job's build. Each build gets its own timeout,
+// so listing many builds doesn't run out of time. Pod info of the build isn't read
+func (as *ArtifactScanner) readBuild(ctx context.Context, jobHistoryPrefix, buildID string) (*JobRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, jobRunTimeout)
+	defer cancel()
+
+	jobDirectoryPrefix, err := as.resolveBuildDirectoryPrefix(ctx, jobHistoryPrefix, buildID)
+	if err != nil {
+		return nil, err
+	}
+	return as.readJobRun(ctx, jobDirectoryPrefix, false)
+}
+
+// Helper function to list IDs of all builds stored within the job's history directory,
+// sorted from the latest to the oldest one
+func (as *ArtifactScanner) listBuildIDs(ctx context.Context, jobHistoryPrefix string) ([]string, error) {
+	var candidates []string
+	if isPresubmitJobHistoryPrefix(jobHistoryPrefix) {
+		// => e.g. "pr-logs/directory/<job-name>/<build-id>.txt"
+		objects, err := as.list(ctx, jobHistoryPrefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range objects {
+			candidates = append(candidates, strings.TrimSuffix(path.Base(o.Name), ".txt"))
+		}
+	} else {
+		// => e.g. "logs/<job-name>/<build-id>/"
+		dirs, err := as.listDirectories(ctx, jobHistoryPrefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			candidates = append(candidates, path.Base(strings.TrimSuffix(d, "/")))
+		}
+	}
+
+	var buildIDs []uint64
+	for _, c := range candidates {
+		// Skip other files, e.g. latest-build.txt
+		if id, err := strconv.ParseUint(c, 10, 64); err == nil {
+			buildIDs = append(buildIDs, id)
+		}
+	}
+	sort.Slice(buildIDs, func(i, j int) bool { return buildIDs[i] > buildIDs[j] })
+
+	result := make([]string, 0, len(buildIDs))
+	for _, id := range buildIDs {
+		result = append(result, strconv.FormatUint(id, 10))
+	}
+	return result, nil
+}
+
+// Helper function to get the prefix of the directory containing artifacts of the job's build
+func (as *ArtifactScanner) resolveBuildDirectoryPrefix(ctx context.Context, jobHistoryPrefix, buildID string) (string, error) {
+	if !isPresubmitJobHistoryPrefix(jobHistoryPrefix) {
+		return jobHistoryPrefix + buildID, nil
+	}
+
+	// => e.g. "gs://test-platform-results/pr-logs/pull/<org>_<repo>/<pr-number>/<job-name>/<build-id>"
+	data, err := as.readObject(ctx, jobHistoryPrefix+buildID+".txt")
+	if err != nil {
+		if errors.Is(err, ErrObjectNotExist) {
+			return "", fmt.Errorf("build %s not found in %s", buildID, jobHistoryPrefix)
+		}
+		return "", err
+	}
+	link := strings.TrimSpace(string(data))
+	bucketPrefix := "gs://" + as.config.Instance.BucketName + "/"
+	if !strings.HasPrefix(link, bucketPrefix) {
+		return "", fmt.Errorf("unexpected link to the build %s: %q", buildID, link)
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(link, bucketPrefix), "/"), nil
+}
+
+// Helper function to get the prefix of the directory holding the history of the job with the given name
+func getJobHistoryPrefix(jobName string) string {
+	if strings.HasPrefix(jobName, "pull-") {
+		return "pr-logs/directory/" + jobName + "/"
+	}
+	return "logs/" + jobName + "/"
+}
+
+func isPresubmitJobHistoryPrefix(jobHistoryPrefix string) bool {
+	return strings.HasPrefix(jobHistoryPrefix, "pr-logs/directory/")
+}
@@ -0,0 +1,56 @@
+package prow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OpenshiftCI is the Prow instance of OpenShift CI
+var OpenshiftCI = Instance{
+	Name:          "openshift-ci",
+	BucketName:    "test-platform-results",
+	ProwURL:       "https://prow.ci.openshift.org",
+	GCSBrowserURL: "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/",
+}
+
+// Instance describes a Prow deployment and the storage its jobs upload artifacts to
+type Instance struct {
+	Name string `json:"name"`
+	// BucketName is the name of the GCS bucket containing artifacts of Prow jobs
+	BucketName string `json:"bucketName"`
+	// ProwURL is the URL of Prow's web UI (deck), e.g. "https://prow.ci.openshift.org"
+	ProwURL string `json:"prowURL"`
+	// GCSBrowserURL is the URL of a web browser pointing to the root of the bucket
+	GCSBrowserURL string `json:"gcsBrowserURL"`
+	// StorageEndpoint overrides the default GCS API endpoint, e.g. "http://localhost:4443/storage/v1/"
+	// for a local fake-GCS server
+	StorageEndpoint string `json:"storageEndpoint"`
+}
+
+// ProwJobYAMLURL returns the URL of the YAML definition of the Prow job with the given ID
+func (i Instance) ProwJobYAMLURL(prowJobID string) string {
+	return strings.TrimSuffix(i.ProwURL, "/") + "/prowjob?prowjob=" + prowJobID
+}
+
+// ProwJobURL returns the Prow job URL for the job stored within the given directory
+// e.g. "logs/<job-name>/<build-id>" => "https://prow.ci.openshift.org/view/gs/test-platform-results/logs/<job-name>/<build-id>"
+func (i Instance) ProwJobURL(jobDirectoryPrefix string) string {
+	return strings.TrimSuffix(i.ProwURL, "/") + "/view/gs/" + i.BucketName + "/" + strings.Trim(jobDirectoryPrefix, "/")
+}
+
+// BrowserURL returns the URL for browsing the given object (or "directory") via GCS web browser
+func (i Instance) BrowserURL(objectName string) string {
+	return strings.TrimSuffix(i.GCSBrowserURL, "/") + "/" + strings.TrimPrefix(objectName, "/")
+}
+
+// JobDirectoryPrefix returns the prefix of the directory (within the bucket) containing
+// artifacts of the Prow job with the given URL
+func (i Instance) JobDirectoryPrefix(prowJobURL string) (string, error) {
+	// => e.g. [ "https://prow.ci.openshift.org/view/gs", "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123" ]
+	sp := strings.Split(prowJobURL, "/"+i.BucketName+"/")
+	if len(sp) != 2 {
+		return "", fmt.Errorf("failed to determine artifact directory's prefix - prow job url: '%s', bucket name: '%s'", prowJobURL, i.BucketName)
+	}
+
+	return strings.TrimSuffix(sp[1], "/"), nil
+}
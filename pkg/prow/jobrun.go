@@ -0,0 +1,275 @@
+package prow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/testgrid/metadata"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+const (
+	startedFileName  = "started.json"
+	finishedFileName = "finished.json"
+	prowJobFileName  = "prowjob.json"
+	podInfoFileName  = "podinfo.json"
+
+	// ResultPending is the result of job runs and steps that haven't finished yet
+	ResultPending = "PENDING"
+)
+
+// JobRun represents a single run of a Prow job, assembled
+// from the metadata files uploaded by Prow and ci-operator
+type JobRun struct {
+	// ID is the build ID of the job run
+	ID      string `json:"id"`
+	JobName string `json:"jobName"`
+	// Type is the type of the Prow job, e.g. "presubmit" or "periodic"
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Started is the time the job run started (from started.json)
+	Started time.Time `json:"started"`
+	// Finished is the time the job run finished (from finished.json), zero if the job is still running
+	Finished time.Time `json:"finished"`
+	// Result is the result of the job run, e.g. "SUCCESS", "FAILURE" or "PENDING"
+	Result string `json:"result"`
+	Passed bool   `json:"passed"`
+	// Revision is the revision (commit SHA) the job run tested
+	Revision string `json:"revision"`
+	// Refs are the git refs the job run tested (from prowjob.json)
+	Refs *v1.Refs `json:"refs,omitempty"`
+	// Pod contains information about the pod the job run was executed in (from podinfo.json)
+	Pod *PodInfo `json:"pod,omitempty"`
+	// Steps contains all steps collected in ArtifactStepMap that have their own finished.json
+	Steps []StepRun `json:"steps,omitempty"`
+}
+
+// StepRun represents a single step of a job run, e.g. an openshift-ci step
+type StepRun struct {
+	Name     ArtifactStepName `json:"name"`
+	Started  time.Time        `json:"started"`
+	Finished time.Time        `json:"finished"`
+	Result   string           `json:"result"`
+	Passed   bool             `json:"passed"`
+}
+
+// PodInfo represents the content of podinfo.json uploaded for jobs executed in a pod
+type PodInfo struct {
+	Pod    *corev1.Pod    `json:"pod"`
+	Events []corev1.Event `json:"events"`
+}
+
+// Duration returns the duration of the job run, zero if the job run hasn't finished yet
+func (r JobRun) Duration() time.Duration {
+	return duration(r.Started, r.Finished)
+}
+
+// Duration returns the duration of the step, zero if the step hasn't finished yet
+// or its start time is unknown
+func (s StepRun) Duration() time.Duration {
+	return duration(s.Started, s.Finished)
+}
+
+func duration(started, finished time.Time) time.Duration {
+	if started.IsZero() || finished.IsZero() {
+		return 0
+	}
+	return finished.Sub(started)
+}
+
+// ParseStarted parses the content of started.json
+func ParseStarted(data []byte) (*metadata.Started, error) {
+	started := &metadata.Started{}
+	if err := json.Unmarshal(data, started); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s into started struct: %+v", data, err)
+	}
+	return started, nil
+}
+
+// ParseFinished parses the content of finished.json
+func ParseFinished(data []byte) (*metadata.Finished, error) {
+	finished := &metadata.Finished{}
+	if err := json.Unmarshal(data, finished); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal %s into finished struct: %+v", data, err)
+	}
+	return finished, nil
+}
+
+// GetJobRun reads metadata files (started.json, finished.json, prowjob.json, podinfo.json)
+// from the root directory of the Prow job and combines them with metadata of the steps
+// collected by Run (steps are only included if "finished.json" matches the FileNameFilter)
+func (as *ArtifactScanner) GetJobRun(ctx context.Context) (*JobRun, error) {
+	jobDirectoryPrefix, err := as.getJobDirectoryPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, as.config.ObjectTimeout)
+	defer cancel()
+
+	jobRun, err := as.readJobRun(ctx, jobDirectoryPrefix, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobRun.Steps, err = as.getStepRuns(); err != nil {
+		return nil, err
+	}
+
+	return jobRun, nil
+}
+
+// Helper function to read metadata files stored within the root directory of the Prow job,
+// podinfo.json is only read if 'withPodInfo' is set
+func (as *ArtifactScanner) readJobRun(ctx context.Context, jobDirectoryPrefix string, withPodInfo bool) (*JobRun, error) {
+	jobRun := &JobRun{
+		ID:      path.Base(jobDirectoryPrefix),
+		JobName: getJobNameFromJobDirectoryPrefix(jobDirectoryPrefix),
+		URL:     as.config.Instance.ProwJobURL(jobDirectoryPrefix),
+		Result:  ResultPending,
+	}
+
+	if data, err := as.readOptionalObject(ctx, jobDirectoryPrefix+"/"+startedFileName); err != nil {
+		return nil, err
+	} else if data != nil {
+		started, err := ParseStarted(data)
+		if err != nil {
+			return nil, err
+		}
+		jobRun.Started = time.Unix(started.Timestamp, 0)
+		jobRun.Revision = started.RepoCommit
+	}
+
+	if data, err := as.readOptionalObject(ctx, jobDirectoryPrefix+"/"+finishedFileName); err != nil {
+		return nil, err
+	} else if data != nil {
+		finished, err := ParseFinished(data)
+		if err != nil {
+			return nil, err
+		}
+		jobRun.Finished, jobRun.Result, jobRun.Passed = finishedDetails(finished)
+		if revision := getRevision(finished); revision != "" {
+			jobRun.Revision = revision
+		}
+	}
+
+	if data, err := as.readOptionalObject(ctx, jobDirectoryPrefix+"/"+prowJobFileName); err != nil {
+		return nil, err
+	} else if data != nil {
+		pj := &v1.ProwJob{}
+		if err := json.Unmarshal(data, pj); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal %s: %+v", prowJobFileName, err)
+		}
+		jobRun.Type = string(pj.Spec.Type)
+		jobRun.Refs = pj.Spec.Refs
+		if jobRun.Revision == "" && pj.Spec.Refs != nil {
+			jobRun.Revision = pj.Spec.Refs.BaseSHA
+			if len(pj.Spec.Refs.Pulls) > 0 {
+				jobRun.Revision = pj.Spec.Refs.Pulls[0].SHA
+			}
+		}
+	}
+
+	if !withPodInfo {
+		return jobRun, nil
+	}
+	if data, err := as.readOptionalObject(ctx, jobDirectoryPrefix+"/"+podInfoFileName); err != nil {
+		return nil, err
+	} else if data != nil {
+		jobRun.Pod = &PodInfo{}
+		if err := json.Unmarshal(data, jobRun.Pod); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal %s: %+v", podInfoFileName, err)
+		}
+	}
+
+	return jobRun, nil
+}
+
+// Helper function to collect metadata of steps stored within the ArtifactStepMap
+func (as *ArtifactScanner) getStepRuns() ([]StepRun, error) {
+	var steps []StepRun
+
+	for stepName, artifacts := range as.ArtifactStepMap {
+		finishedArtifact, ok := artifacts[finishedFileName]
+		if !ok {
+			continue
+		}
+		data, err := finishedArtifact.ReadContent()
+		if err != nil {
+			return nil, err
+		}
+		finished, err := ParseFinished(data)
+		if err != nil {
+			return nil, err
+		}
+
+		step := StepRun{Name: stepName}
+		step.Finished, step.Result, step.Passed = finishedDetails(finished)
+
+		if startedArtifact, ok := artifacts[startedFileName]; ok {
+			data, err := startedArtifact.ReadContent()
+			if err != nil {
+				return nil, err
+			}
+			started, err := ParseStarted(data)
+			if err != nil {
+				return nil, err
+			}
+			step.Started = time.Unix(started.Timestamp, 0)
+		}
+
+		steps = append(steps, step)
+	}
+
+	sort.Slice(steps, func(i, j int) bool {
+		if !steps[i].Started.Equal(steps[j].Started) {
+			return steps[i].Started.Before(steps[j].Started)
+		}
+		return steps[i].Name < steps[j].Name
+	})
+
+	return steps, nil
+}
+
+// Helper function to read an object that might not exist - returns nil data in such case
+func (as *ArtifactScanner) readOptionalObject(ctx context.Context, name string) ([]byte, error) {
+	data, err := as.readObject(ctx, name)
+	if errors.Is(err, ErrObjectNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func finishedDetails(finished *metadata.Finished) (finishedAt time.Time, result string, passed bool) {
+	if finished.Timestamp != nil {
+		finishedAt = time.Unix(*finished.Timestamp, 0)
+	}
+	if finished.Passed != nil {
+		passed = *finished.Passed
+	}
+	result = finished.Result
+	if result == "" {
+		switch {
+		case finished.Passed == nil:
+			result = ResultPending
+		case passed:
+			result = "SUCCESS"
+		default:
+			result = "FAILURE"
+		}
+	}
+	return finishedAt, result, passed
+}
+
+func getRevision(finished *metadata.Finished) string {
+	if v, ok := finished.Metadata.String(metadata.JobVersion); ok && v != nil {
+		return *v
+	}
+	return finished.DeprecatedRevision
+}
@@ -3,17 +3,15 @@ package prow
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
+	"sync"
 
 	"cloud.google.com/go/storage"
-	"golang.org/x/exp/slices"
-	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"k8s.io/klog/v2"
 	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
@@ -21,25 +19,85 @@ import (
 )
 
 // NewArtifactScanner creates a new instance of ArtifactScanner,
-// requires a valid ScannerConfig
+// requires a valid ScannerConfig. If the ScannerConfig doesn't
+// contain an ArtifactSource, the artifacts are read from GCS
 func NewArtifactScanner(cfg ScannerConfig) (*ArtifactScanner, error) {
-	ctx := context.Background()
-	client, err := storage.NewClient(ctx, option.WithoutAuthentication())
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+	if cfg.ObjectTimeout <= 0 {
+		cfg.ObjectTimeout = defaultObjectTimeout
+	}
+	if cfg.MaxInMemorySize <= 0 {
+		cfg.MaxInMemorySize = defaultMaxInMemorySize
+	}
+	if cfg.Instance.BucketName == "" {
+		cfg.Instance = OpenshiftCI
+	}
+	switch cfg.Layout {
+	case "":
+		cfg.Layout = LayoutAuto
+	case LayoutAuto, LayoutCIOperator, LayoutGeneric:
+	default:
+		return nil, fmt.Errorf("unknown artifact layout %q, expected one of %q, %q or %q", cfg.Layout, LayoutAuto, LayoutCIOperator, LayoutGeneric)
+	}
+	if cfg.Retry.MaxAttempts <= 0 {
+		cfg.Retry.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.Retry.InitialBackoff <= 0 {
+		cfg.Retry.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.Retry.MaxBackoff <= 0 {
+		cfg.Retry.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.Retry.MaxBackoff < cfg.Retry.InitialBackoff {
+		cfg.Retry.MaxBackoff = cfg.Retry.InitialBackoff
+	}
+
+	jobTargetMatchers, err := compileJobTargetRules(append(append([]JobTargetRule{}, cfg.JobTargetRules...), DefaultJobTargetRules...))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new GCS client: %+v", err)
+		return nil, fmt.Errorf("invalid job target rules: %+v", err)
+	}
+
+	filter, err := compileArtifactFilter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	as := &ArtifactScanner{
+		config:            cfg,
+		source:            cfg.ArtifactSource,
+		jobTargetMatchers: jobTargetMatchers,
+		filter:            filter,
+		httpClient:        &http.Client{Timeout: httpTimeout},
+	}
+
+	if as.source == nil {
+		ctx := context.Background()
+		opts := []option.ClientOption{option.WithoutAuthentication()}
+		if cfg.Instance.StorageEndpoint != "" {
+			opts = append(opts, option.WithEndpoint(cfg.Instance.StorageEndpoint))
+		}
+		client, err := storage.NewClient(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create new GCS client: %+v", err)
+		}
+		as.Client = client
+		as.source = NewGCSSource(client, cfg.Instance.BucketName)
+	}
+
+	if cfg.CacheDir != "" {
+		as.source = NewCachedSource(as.source, cfg.CacheDir, cfg.Instance.BucketName, cfg.CacheMaxSize)
 	}
 
-	return &ArtifactScanner{
-		Client: client,
-		config: cfg,
-	}, nil
+	return as, nil
 }
 
 // Run processes the artifacts associated with the Prow job and stores required files
 // with their associated openshift-ci step names and their content in ArtifactStepMap.
-func (as *ArtifactScanner) Run() error {
+func (as *ArtifactScanner) Run(ctx context.Context) error {
 	// Determine job target and Prow job URL.
-	jobTarget, pjURL, err := as.determineJobDetails()
+	jobTarget, pjURL, err := as.determineJobDetails(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to determine job details: %+v", err)
 	}
@@ -49,55 +107,147 @@ func (as *ArtifactScanner) Run() error {
 		return fmt.Errorf("failed to get artifact directory prefix: %+v", err)
 	}
 
-	// Iterate over storage objects.
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*2)
+	// List storage objects.
+	listCtx, cancel := context.WithTimeout(ctx, listTimeout)
 	defer cancel()
-	as.bucketHandle = as.Client.Bucket(bucketName)
-	it := as.bucketHandle.Objects(ctx, &storage.Query{Prefix: artifactDirectoryPrefix})
+	objects, err := as.list(listCtx, artifactDirectoryPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list storage objects: %+v", err)
+	}
 
 	// Process storage objects.
-	if err := as.processStorageObjects(ctx, it, artifactDirectoryPrefix, pjURL); err != nil {
+	if err := as.processStorageObjects(ctx, objects, artifactDirectoryPrefix, pjURL); err != nil {
 		return fmt.Errorf("failed to process storage objects: %+v", err)
 	}
 
 	return nil
 }
 
-// Helper function to determine job details.
-func (as *ArtifactScanner) determineJobDetails() (jobTarget, pjURL string, err error) {
-	switch {
-	case as.config.ProwJobID != "":
-		pjYAML, err := getProwJobYAML(as.config.ProwJobID)
+// ReadJobFile returns the content of the file with the given name stored
+// within the root directory of the Prow job, e.g. "build-log.txt"
+func (as *ArtifactScanner) ReadJobFile(ctx context.Context, fileName string) ([]byte, error) {
+	jobDirectoryPrefix, err := as.getJobDirectoryPrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, as.config.ObjectTimeout)
+	defer cancel()
+
+	return as.readObject(ctx, jobDirectoryPrefix+"/"+fileName)
+}
+
+// Helper function to read the whole content of the object with the given name
+func (as *ArtifactScanner) readObject(ctx context.Context, name string) ([]byte, error) {
+	var data []byte
+	err := retry(ctx, as.config.Retry, "reading "+name, func() error {
+		rc, err := as.source.NewReader(ctx, name)
 		if err != nil {
-			return "", "", fmt.Errorf("failed to get Prow job YAML: %+v", err)
+			return err
+		}
+		defer rc.Close()
+
+		if data, err = io.ReadAll(rc); err != nil {
+			return fmt.Errorf("cannot read from storage reader: %w", err)
 		}
-		jobTarget, err = determineJobTargetFromYAML(pjYAML)
+		return nil
+	})
+	return data, err
+}
+
+// Helper function to list objects with the given prefix, retrying on transient errors
+func (as *ArtifactScanner) list(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+	err := retry(ctx, as.config.Retry, "listing "+prefix, func() (err error) {
+		objects, err = as.source.List(ctx, prefix)
+		return err
+	})
+	return objects, err
+}
+
+// Helper function to list "directories" within the given prefix, retrying on transient errors
+func (as *ArtifactScanner) listDirectories(ctx context.Context, prefix string) ([]string, error) {
+	var dirs []string
+	err := retry(ctx, as.config.Retry, "listing directories of "+prefix, func() (err error) {
+		dirs, err = as.source.ListDirectories(ctx, prefix)
+		return err
+	})
+	return dirs, err
+}
+
+// BrowserURL returns the URL for browsing the given object (or "directory")
+// via GCS web browser of the scanned Prow instance
+func (as *ArtifactScanner) BrowserURL(objectName string) string {
+	return as.config.Instance.BrowserURL(objectName)
+}
+
+// Helper function to get the prefix of the directory containing the Prow job's artifacts
+func (as *ArtifactScanner) getJobDirectoryPrefix(ctx context.Context) (string, error) {
+	pjURL := as.prowJobURL
+	if pjURL == "" {
+		pjURL = as.config.ProwJobURL
+	}
+	if pjURL == "" && as.config.ProwJobID != "" {
+		pjYAML, err := as.getProwJobYAML(ctx, as.config.Instance.ProwJobYAMLURL(as.config.ProwJobID))
 		if err != nil {
-			return "", "", fmt.Errorf("failed to determine job target from YAML: %+v", err)
+			return "", fmt.Errorf("failed to get Prow job YAML: %+v", err)
+		}
+		pjURL = pjYAML.Status.URL
+	}
+	if pjURL == "" {
+		return "", fmt.Errorf("ScannerConfig doesn't contain either ProwJobID or ProwJobURL")
+	}
+	return as.config.Instance.JobDirectoryPrefix(pjURL)
+}
+
+// Helper function to determine job details. The returned job target
+// is empty if the job's artifacts should be scanned using the generic layout
+func (as *ArtifactScanner) determineJobDetails(ctx context.Context) (jobTarget, pjURL string, err error) {
+	var pjYAML *v1.ProwJob
+	switch {
+	case as.config.ProwJobID != "":
+		if pjYAML, err = as.getProwJobYAML(ctx, as.config.Instance.ProwJobYAMLURL(as.config.ProwJobID)); err != nil {
+			return "", "", fmt.Errorf("failed to get Prow job YAML: %+v", err)
 		}
 		pjURL = pjYAML.Status.URL
 
 	case as.config.ProwJobURL != "":
 		pjURL = as.config.ProwJobURL
-		jobTarget, err = determineJobTargetFromProwJobURL(pjURL)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to determine job target from Prow job URL: %+v", err)
-		}
 
 	default:
 		return "", "", fmt.Errorf("ScannerConfig doesn't contain either ProwJobID or ProwJobURL")
 	}
+	as.prowJobURL = pjURL
+
+	if as.config.Layout == LayoutGeneric {
+		return "", pjURL, nil
+	}
 
+	if pjYAML != nil {
+		if jobTarget, err = determineJobTargetFromYAML(pjYAML); err == nil {
+			return jobTarget, pjURL, nil
+		}
+		klog.Infof("%+v - trying to determine the target from job target rules", err)
+		if jobTarget, err = as.resolveJobTarget(ctx, pjYAML.Spec.Job, pjURL); err != nil {
+			return "", "", fmt.Errorf("failed to determine job target from YAML: %+v", err)
+		}
+		return jobTarget, pjURL, nil
+	}
+
+	jobDirectoryPrefix, err := as.config.Instance.JobDirectoryPrefix(pjURL)
+	if err != nil {
+		return "", "", err
+	}
+	jobTarget, err = as.resolveJobTarget(ctx, getJobNameFromJobDirectoryPrefix(jobDirectoryPrefix), pjURL)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to determine job target from Prow job URL: %+v", err)
+	}
 	return jobTarget, pjURL, nil
 }
 
 // Helper function to process storage objects.
-func (as *ArtifactScanner) processStorageObjects(ctx context.Context, it *storage.ObjectIterator, artifactDirectoryPrefix, pjURL string) error {
-	var objectAttrs *storage.ObjectAttrs
-	var err error
-
-	objectAttrs, err = it.Next()
-	if errors.Is(err, iterator.Done) {
+func (as *ArtifactScanner) processStorageObjects(ctx context.Context, objects []ObjectAttrs, artifactDirectoryPrefix, pjURL string) error {
+	if len(objects) == 0 {
 		// No files present within the target directory - get the root build-log.txt instead.
 		if err := as.handleEmptyDirectory(ctx, pjURL, artifactDirectoryPrefix); err != nil {
 			return err
@@ -105,105 +255,183 @@ func (as *ArtifactScanner) processStorageObjects(ctx context.Context, it *storag
 		return nil
 	}
 
-	// Iterate over storage objects.
-	for {
+	// Collect required storage objects.
+	var artifacts []requiredArtifact
+	for _, objectAttrs := range objects {
+		artifact, required, err := as.processRequiredFile(objectAttrs, artifactDirectoryPrefix)
 		if err != nil {
-			return fmt.Errorf("failed to iterate over storage objects: %+v", err)
-		}
-		fullArtifactName := objectAttrs.Name
-		if as.isRequiredFile(fullArtifactName) {
-			if err := as.processRequiredFile(fullArtifactName, artifactDirectoryPrefix); err != nil {
-				return err
-			}
+			return err
 		}
-
-		objectAttrs, err = it.Next()
-		if errors.Is(err, iterator.Done) {
-			break
+		if required {
+			artifacts = append(artifacts, artifact)
 		}
 	}
 
-	return nil
+	return as.downloadArtifacts(ctx, artifacts)
 }
 
 // Helper function to handle an empty directory.
 func (as *ArtifactScanner) handleEmptyDirectory(ctx context.Context, pjURL, artifactDirectoryPrefix string) error {
 	klog.Infof("For the job (%s), there are no files present within the directory with prefix: `%s`", pjURL, artifactDirectoryPrefix)
 
-	// Set up default file name filter.
 	fileName := "build-log.txt"
-	as.config.FileNameFilter = []string{fileName}
 
 	// Check for build log file.
-	sp := strings.Split(pjURL, "/"+bucketName+"/")
-	if len(sp) != 2 {
-		return fmt.Errorf("failed to determine artifact directory's prefix - Prow job URL: '%s', bucket name: '%s'", pjURL, bucketName)
+	jobDirectoryPrefix, err := as.config.Instance.JobDirectoryPrefix(pjURL)
+	if err != nil {
+		return err
 	}
-	buildLogPrefix := sp[1] + "/" + fileName
+	buildLogPrefix := jobDirectoryPrefix + "/" + fileName
 
 	// Iterate over build log files.
-	it := as.bucketHandle.Objects(ctx, &storage.Query{Prefix: buildLogPrefix})
-	for {
-		attrs, err := it.Next()
-		if errors.Is(err, iterator.Done) {
-			break
+	objects, err := as.list(ctx, buildLogPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to list storage objects: %+v", err)
+	}
+	var artifacts []requiredArtifact
+	for _, attrs := range objects {
+		artifacts = append(artifacts, requiredArtifact{fileName: fileName, fullName: attrs.Name, stepName: "/", size: attrs.Size})
+	}
+
+	return as.downloadArtifacts(ctx, artifacts)
+}
+
+// Helper function to process a storage object. Returns false
+// if the object doesn't match the include/exclude rules of the ScannerConfig
+func (as *ArtifactScanner) processRequiredFile(objectAttrs ObjectAttrs, artifactDirectoryPrefix string) (requiredArtifact, bool, error) {
+	fullArtifactName := objectAttrs.Name
+	parentStepName, err := getParentStepName(fullArtifactName, artifactDirectoryPrefix)
+	if err != nil {
+		return requiredArtifact{}, false, err
+	}
+
+	fileName, err := getFileName(fullArtifactName, artifactDirectoryPrefix)
+	if err != nil {
+		return requiredArtifact{}, false, err
+	}
+
+	if !as.filter.matches(artifactAttrs{path: fullArtifactName, name: fileName, step: parentStepName, size: objectAttrs.Size}, false) {
+		return requiredArtifact{}, false, nil
+	}
+
+	return requiredArtifact{fileName: fileName, fullName: fullArtifactName, stepName: parentStepName, size: objectAttrs.Size}, true, nil
+}
+
+// Helper function to download the given artifacts using a pool of workers
+// limited by ScannerConfig.Concurrency. Artifacts that cannot be downloaded
+// (e.g. because of hitting ScannerConfig.ObjectTimeout) are logged and recorded in SkippedArtifacts.
+// Returns an error if the context is canceled before all artifacts are downloaded
+func (as *ArtifactScanner) downloadArtifacts(ctx context.Context, artifacts []requiredArtifact) error {
+	forEachConcurrently(artifacts, as.config.Concurrency, func(artifact requiredArtifact) {
+		if ctx.Err() != nil {
+			return
 		}
-		if err != nil {
-			return fmt.Errorf("failed to iterate over storage objects: %+v", err)
+		if err := as.downloadArtifact(ctx, artifact); err != nil {
+			klog.Warningf("skipping artifact %s: %+v", artifact.fullName, err)
+			as.mu.Lock()
+			as.SkippedArtifacts = append(as.SkippedArtifacts, artifact.fullName)
+			as.mu.Unlock()
 		}
-		fullArtifactName := attrs.Name
+	})
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("downloading of artifacts was interrupted: %w", err)
+	}
+	return nil
+}
 
-		if err := as.initArtifactStepMap(ctx, fileName, fullArtifactName, "/"); err != nil {
-			return err
-		}
+// Helper function to call 'fn' for each of the given items
+// using a pool of 'concurrency' workers
+func forEachConcurrently[T any](items []T, concurrency int, fn func(T)) {
+	queue := make(chan T)
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				fn(item)
+			}
+		}()
 	}
 
-	return nil
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
 }
 
-// Helper function to process a required file.
-func (as *ArtifactScanner) processRequiredFile(fullArtifactName, artifactDirectoryPrefix string) error {
-	parentStepName, err := getParentStepName(fullArtifactName, artifactDirectoryPrefix)
+// Helper function to download a single artifact and store it in the ArtifactStepMap
+func (as *ArtifactScanner) downloadArtifact(ctx context.Context, artifact requiredArtifact) error {
+	return retry(ctx, as.config.Retry, "downloading "+artifact.fullName, func() error {
+		return as.downloadArtifactOnce(ctx, artifact)
+	})
+}
+
+// Helper function to make a single attempt to download an artifact, limited by ScannerConfig.ObjectTimeout
+func (as *ArtifactScanner) downloadArtifactOnce(ctx context.Context, artifact requiredArtifact) error {
+	ctx, cancel := context.WithTimeout(ctx, as.config.ObjectTimeout)
+	defer cancel()
+
+	rc, err := as.source.NewReader(ctx, artifact.fullName)
 	if err != nil {
 		return err
 	}
+	defer rc.Close()
 
-	if slices.Contains(as.config.StepsToSkip, parentStepName) {
-		klog.Infof("Skipping step name %s", parentStepName)
+	if as.config.SpoolDir != "" && artifact.size > as.config.MaxInMemorySize {
+		path, err := as.spoolArtifact(rc, artifact.fullName)
+		if err != nil {
+			return err
+		}
+		as.initArtifactStepMap(artifact.fileName, artifact.stepName, Artifact{FullName: artifact.fullName, Path: path, Size: artifact.size})
 		return nil
 	}
 
-	fileName, err := getFileName(fullArtifactName, artifactDirectoryPrefix)
+	data, err := io.ReadAll(rc)
 	if err != nil {
-		return err
+		return fmt.Errorf("cannot read from storage reader: %w", err)
 	}
 
-	if err := as.initArtifactStepMap(context.Background(), fileName, fullArtifactName, parentStepName); err != nil {
-		return err
-	}
+	as.initArtifactStepMap(artifact.fileName, artifact.stepName, Artifact{Content: string(data), FullName: artifact.fullName, Size: int64(len(data))})
 
 	return nil
 }
 
-// Helper function to initialise/update the ArtifactStepMap with content
-// of a file with given 'fileName', within the given 'parentStepName'
-func (as *ArtifactScanner) initArtifactStepMap(ctx context.Context, fileName, fullArtifactName, parentStepName string) error {
-	rc, err := as.bucketHandle.Object(fullArtifactName).NewReader(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to create objecthandle for %s: %+v", fullArtifactName, err)
+// Helper function to store the content of an artifact within the ScannerConfig.SpoolDir,
+// keeping the directory structure of the storage. Returns the path to the stored file
+func (as *ArtifactScanner) spoolArtifact(r io.Reader, fullArtifactName string) (string, error) {
+	path := filepath.Join(as.config.SpoolDir, filepath.FromSlash(filepath.Clean("/"+fullArtifactName)))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create spool directory for %s: %+v", fullArtifactName, err)
 	}
-	data, err := io.ReadAll(rc)
+
+	f, err := os.Create(path)
 	if err != nil {
-		return fmt.Errorf("cannot read from storage reader: %+v", err)
+		return "", fmt.Errorf("failed to create spool file for %s: %+v", fullArtifactName, err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return "", fmt.Errorf("failed to spool %s to %s: %w", fullArtifactName, path, err)
 	}
 
-	artifact := Artifact{Content: string(data), FullName: fullArtifactName}
+	return path, nil
+}
+
+// Helper function to initialise/update the ArtifactStepMap with given artifact
+// of a file with given 'fileName', within the given 'parentStepName'
+func (as *ArtifactScanner) initArtifactStepMap(fileName, parentStepName string, artifact Artifact) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
 	newArtifactMap := ArtifactFilenameMap{ArtifactFilename(fileName): artifact}
 
 	// No artifact step map not initialized yet
 	if as.ArtifactStepMap == nil {
 		as.ArtifactStepMap = map[ArtifactStepName]ArtifactFilenameMap{ArtifactStepName(parentStepName): newArtifactMap}
-		return nil
+		return
 	}
 
 	// Already have a record of an artifact being mapped to a step name
@@ -213,29 +441,27 @@ func (as *ArtifactScanner) initArtifactStepMap(ctx context.Context, fileName, fu
 	} else { // Artifact map initialized, but the artifact filename does not belong to any collected step
 		as.ArtifactStepMap[ArtifactStepName(parentStepName)] = newArtifactMap
 	}
-
-	return nil
 }
 
-// Helper function to check if a file with given 'fullArtifactName',
-// matches the file-name filter(s) defined within ScannerConfig struct
-func (as *ArtifactScanner) isRequiredFile(fullArtifactName string) bool {
-	return slices.ContainsFunc(as.config.FileNameFilter, func(s string) bool {
-		re := regexp.MustCompile(s)
-		return re.MatchString(fullArtifactName)
-	})
-}
-
-func getProwJobYAML(jobID string) (*v1.ProwJob, error) {
-	r, err := http.Get(prowJobYAMLPrefix + jobID)
+func (as *ArtifactScanner) getProwJobYAML(ctx context.Context, prowJobYAMLURL string) (*v1.ProwJob, error) {
 	errTemplate := "failed to get prow job YAML:"
-	if err != nil {
-		return nil, fmt.Errorf("%s %s", errTemplate, err)
-	}
-	if r.StatusCode > 299 {
-		return nil, fmt.Errorf("%s got response status code %v", errTemplate, r.StatusCode)
-	}
-	body, err := io.ReadAll(r.Body)
+	var body []byte
+	err := retry(ctx, as.config.Retry, "getting "+prowJobYAMLURL, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, prowJobYAMLURL, nil)
+		if err != nil {
+			return err
+		}
+		r, err := as.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer r.Body.Close()
+		if r.StatusCode > 299 {
+			return &httpStatusError{url: prowJobYAMLURL, statusCode: r.StatusCode}
+		}
+		body, err = io.ReadAll(r.Body)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%s %s", errTemplate, err)
 	}
@@ -249,6 +475,9 @@ func getProwJobYAML(jobID string) (*v1.ProwJob, error) {
 
 func determineJobTargetFromYAML(pjYAML *v1.ProwJob) (jobTarget string, err error) {
 	errPrefix := "failed to determine job target:"
+	if pjYAML.Spec.PodSpec == nil || len(pjYAML.Spec.PodSpec.Containers) == 0 {
+		return "", fmt.Errorf("%s Prow job's pod spec doesn't contain any containers", errPrefix)
+	}
 	args := pjYAML.Spec.PodSpec.Containers[0].Args
 	for _, arg := range args {
 		if strings.Contains(arg, "--target") {
@@ -263,6 +492,31 @@ func determineJobTargetFromYAML(pjYAML *v1.ProwJob) (jobTarget string, err error
 	return "", fmt.Errorf("%s expected %+v to contain arg --target", errPrefix, args)
 }
 
+// Open returns a reader of the artifact's content, regardless of whether
+// the content is held in memory or it was spooled to disk
+func (a Artifact) Open() (io.ReadCloser, error) {
+	if a.Path == "" {
+		return io.NopCloser(strings.NewReader(a.Content)), nil
+	}
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spooled artifact %s: %+v", a.FullName, err)
+	}
+	return f, nil
+}
+
+// ReadContent returns the whole content of the artifact
+func (a Artifact) ReadContent() ([]byte, error) {
+	if a.Path == "" {
+		return []byte(a.Content), nil
+	}
+	data, err := os.ReadFile(a.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spooled artifact %s: %+v", a.FullName, err)
+	}
+	return data, nil
+}
+
 // ParseJobSpec parses and then returns the openshift job spec data
 func ParseJobSpec(jobSpecData string) (*OpenshiftJobSpec, error) {
 	openshiftJobSpec := &OpenshiftJobSpec{}
@@ -273,33 +527,18 @@ func ParseJobSpec(jobSpecData string) (*OpenshiftJobSpec, error) {
 	return openshiftJobSpec, nil
 }
 
-func determineJobTargetFromProwJobURL(prowJobURL string) (jobTarget string, err error) {
-	switch {
-	case strings.Contains(prowJobURL, "pull-ci-redhat-appstudio-infra-deployments"):
-		// prow URL is from infra-deployments repo
-		jobTarget = "appstudio-e2e-tests"
-	case strings.Contains(prowJobURL, "pull-ci-redhat-appstudio-e2e-tests"):
-		// prow URL is from e2e-tests repo
-		jobTarget = "redhat-appstudio-e2e"
-	case strings.Contains(prowJobURL, "pull-ci-redhat-appstudio-integration-service"):
-		// prow URL is from integration-service repo
-		jobTarget = "integration-service-e2e"
-	default:
-		return "", fmt.Errorf("unable to determine the target from the ProwJobURL: %s", prowJobURL)
-	}
-
-	return jobTarget, nil
-}
-
 func getArtifactsDirectoryPrefix(artifactScanner *ArtifactScanner, prowJobURL, jobTarget string) (string, error) {
-	// => e.g. [ "https://prow.ci.openshift.org/view/gs", "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123" ]
-	sp := strings.Split(prowJobURL, "/"+bucketName+"/")
-	if len(sp) != 2 {
-		return "", fmt.Errorf("failed to determine artifact directory's prefix - prow job url: '%s', bucket name: '%s'", prowJobURL, bucketName)
+	jobDirectoryPrefix, err := artifactScanner.config.Instance.JobDirectoryPrefix(prowJobURL)
+	if err != nil {
+		return "", err
 	}
 
 	// => e.g. "pr-logs/pull/redhat-appstudio_infra-deployments/123/pull-ci-redhat-appstudio-infra-deployments-main-appstudio-e2e-tests/123/artifacts/appstudio-e2e-tests/"
-	artifactDirectoryPrefix := sp[1] + "/artifacts/" + jobTarget + "/"
+	artifactDirectoryPrefix := jobDirectoryPrefix + "/artifacts/" + jobTarget + "/"
+	if jobTarget == "" {
+		// Generic layout - "artifacts/" directory is the root of the steps
+		artifactDirectoryPrefix = jobDirectoryPrefix + "/artifacts/"
+	}
 	artifactScanner.ArtifactDirectoryPrefix = artifactDirectoryPrefix
 
 	return artifactDirectoryPrefix, nil
@@ -315,6 +554,10 @@ func getParentStepName(fullArtifactName, artifactDirectoryPrefix string) (string
 
 	// => e.g. [ "redhat-appstudio-e2e", "artifacts", "e2e-report.xml" ]
 	sp = strings.Split(parentStepFilePath, "/")
+	if len(sp) == 1 {
+		// Files stored directly within the artifact directory don't belong to any step
+		return "/", nil
+	}
 	parentStepName := sp[0]
 
 	return parentStepName, nil
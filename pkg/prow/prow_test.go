@@ -0,0 +1,303 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testBucket is a directory mirroring the layout of the bucket of the OpenshiftCI instance. It contains
+// a job run by ci-operator (with the target "e2e") and a job using the generic layout
+var testBucket = filepath.Join("testdata", "bucket")
+
+const (
+	ciOperatorJobPrefix = "logs/periodic-ci-e2e/100"
+	genericJobPrefix    = "logs/periodic-generic/5"
+)
+
+// Helper function to return names of objects (or "directories") with the given prefix removed, sorted
+func trimmedNames(names []string, prefix string) []string {
+	trimmed := make([]string, 0, len(names))
+	for _, name := range names {
+		trimmed = append(trimmed, strings.TrimPrefix(name, prefix))
+	}
+	sort.Strings(trimmed)
+	return trimmed
+}
+
+// Helper function to return names of the objects from the given attributes
+func objectNames(objects []ObjectAttrs) []string {
+	names := make([]string, 0, len(objects))
+	for _, o := range objects {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+// Helper function to read the whole content of the object with the given name from the source
+func readAll(t *testing.T, source ArtifactSource, name string) string {
+	t.Helper()
+	rc, err := source.NewReader(context.Background(), name)
+	if err != nil {
+		t.Fatalf("failed to open %s: %+v", name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("failed to read %s: %+v", name, err)
+	}
+	return string(data)
+}
+
+func TestLocalSource(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocalSource(testBucket)
+
+	objects, err := s.List(ctx, genericJobPrefix+"/artifacts/t")
+	if err != nil {
+		t.Fatalf("List failed: %+v", err)
+	}
+	if got, want := trimmedNames(objectNames(objects), genericJobPrefix+"/"), []string{"artifacts/tests/build-log.txt", "artifacts/tests/junit.xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %q, want %q", got, want)
+	}
+	if objects[0].Size == 0 {
+		t.Errorf("size of %s isn't set", objects[0].Name)
+	}
+
+	dirs, err := s.ListDirectories(ctx, ciOperatorJobPrefix+"/artifacts/")
+	if err != nil {
+		t.Fatalf("ListDirectories failed: %+v", err)
+	}
+	if got, want := trimmedNames(dirs, ciOperatorJobPrefix+"/artifacts/"), []string{"build-logs/", "e2e/"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListDirectories() = %q, want %q", got, want)
+	}
+
+	if got := readAll(t, s, genericJobPrefix+"/artifacts/tests/build-log.txt"); got != "tests log\n" {
+		t.Errorf("content = %q, want %q", got, "tests log\n")
+	}
+	for _, name := range []string{genericJobPrefix + "/missing.txt", "../bucket/" + genericJobPrefix + "/build-log.txt"} {
+		if _, err := s.NewReader(ctx, name); !errors.Is(err, ErrObjectNotExist) {
+			t.Errorf("NewReader(%q) error = %v, want %v", name, err, ErrObjectNotExist)
+		}
+	}
+}
+
+func TestLocalJobSource(t *testing.T) {
+	ctx := context.Background()
+	mount := "pr-logs/pull/org_repo/1/job/2"
+	s := NewLocalJobSource(filepath.Join(testBucket, genericJobPrefix), mount)
+
+	objects, err := s.List(ctx, "pr-logs/")
+	if err != nil {
+		t.Fatalf("List failed: %+v", err)
+	}
+	want := []string{"artifacts/build-log.txt", "artifacts/tests/build-log.txt", "artifacts/tests/junit.xml", "build-log.txt"}
+	if got := trimmedNames(objectNames(objects), mount+"/"); !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %q, want %q", got, want)
+	}
+	if objects, err := s.List(ctx, "logs/"); err != nil || len(objects) != 0 {
+		t.Errorf("List() of objects outside the job = %q, %v, want none", objectNames(objects), err)
+	}
+
+	dirs, err := s.ListDirectories(ctx, mount+"/artifacts/")
+	if err != nil {
+		t.Fatalf("ListDirectories failed: %+v", err)
+	}
+	if got := trimmedNames(dirs, mount+"/artifacts/"); !reflect.DeepEqual(got, []string{"tests/"}) {
+		t.Errorf("ListDirectories() = %q, want %q", got, []string{"tests/"})
+	}
+
+	if got := readAll(t, s, mount+"/build-log.txt"); got != "generic job log\n" {
+		t.Errorf("content = %q, want %q", got, "generic job log\n")
+	}
+	if _, err := s.NewReader(ctx, genericJobPrefix+"/build-log.txt"); !errors.Is(err, ErrObjectNotExist) {
+		t.Errorf("NewReader() of an object outside the job error = %v, want %v", err, ErrObjectNotExist)
+	}
+}
+
+// Helper function to return names of the files found by the scanner within each step, sorted
+func stepFiles(m map[ArtifactStepName]ArtifactFilenameMap) map[string][]string {
+	files := map[string][]string{}
+	for step, artifacts := range m {
+		for name := range artifacts {
+			files[string(step)] = append(files[string(step)], string(name))
+		}
+		sort.Strings(files[string(step)])
+	}
+	return files
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        ScannerConfig
+		wantPrefix string
+		wantFiles  map[string][]string
+	}{
+		{
+			name:       "ci-operator layout",
+			cfg:        ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix)},
+			wantPrefix: ciOperatorJobPrefix + "/artifacts/e2e/",
+			wantFiles: map[string][]string{
+				"redhat-appstudio-e2e": {"build-log.txt", "finished.json", "junit.xml"},
+				"gather-extra":         {"build-log.txt"},
+			},
+		},
+		{
+			name:       "skipped steps",
+			cfg:        ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix), StepsToSkip: []string{"gather-extra"}},
+			wantPrefix: ciOperatorJobPrefix + "/artifacts/e2e/",
+			wantFiles:  map[string][]string{"redhat-appstudio-e2e": {"build-log.txt", "finished.json", "junit.xml"}},
+		},
+		{
+			name:       "generic layout",
+			cfg:        ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(genericJobPrefix)},
+			wantPrefix: genericJobPrefix + "/artifacts/",
+			wantFiles: map[string][]string{
+				"/":     {"build-log.txt"},
+				"tests": {"build-log.txt", "junit.xml"},
+			},
+		},
+		{
+			name:       "include and exclude rules",
+			cfg:        ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix), IncludeRules: []string{"name=*.txt", "name=*.xml"}, ExcludeRules: []string{"step=gather-*"}},
+			wantPrefix: ciOperatorJobPrefix + "/artifacts/e2e/",
+			wantFiles:  map[string][]string{"redhat-appstudio-e2e": {"build-log.txt", "junit.xml"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.ArtifactSource = NewLocalSource(testBucket)
+			if tt.cfg.FileNameFilter == nil && tt.cfg.IncludeRules == nil {
+				tt.cfg.FileNameFilter = []string{`build-log\.txt$`, `finished\.json$`, `junit\.xml$`}
+			}
+			scanner, err := NewArtifactScanner(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create the scanner: %+v", err)
+			}
+			if err := scanner.Run(context.Background()); err != nil {
+				t.Fatalf("Run failed: %+v", err)
+			}
+
+			if scanner.ArtifactDirectoryPrefix != tt.wantPrefix {
+				t.Errorf("artifact directory prefix = %q, want %q", scanner.ArtifactDirectoryPrefix, tt.wantPrefix)
+			}
+			if got := stepFiles(scanner.ArtifactStepMap); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %q, want %q", got, tt.wantFiles)
+			}
+			if len(scanner.SkippedArtifacts) > 0 {
+				t.Errorf("skipped artifacts: %q", scanner.SkippedArtifacts)
+			}
+		})
+	}
+}
+
+func TestRunReadsContent(t *testing.T) {
+	spoolDir := t.TempDir()
+	scanner, err := NewArtifactScanner(ScannerConfig{
+		ArtifactSource:  NewLocalSource(testBucket),
+		ProwJobURL:      OpenshiftCI.ProwJobURL(genericJobPrefix),
+		FileNameFilter:  []string{`/tests/`},
+		SpoolDir:        spoolDir,
+		MaxInMemorySize: 12,
+	})
+	if err != nil {
+		t.Fatalf("failed to create the scanner: %+v", err)
+	}
+	if err := scanner.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %+v", err)
+	}
+
+	// The build log fits into the memory, the JUnit report is spooled to disk
+	log := scanner.ArtifactStepMap["tests"]["build-log.txt"]
+	if log.Content != "tests log\n" || log.Path != "" {
+		t.Errorf("build log = %+v, want its content in memory", log)
+	}
+	junit := scanner.ArtifactStepMap["tests"]["junit.xml"]
+	if junit.Content != "" || !strings.HasPrefix(junit.Path, spoolDir) {
+		t.Errorf("JUnit report = %+v, want it spooled to %s", junit, spoolDir)
+	}
+	for _, a := range []Artifact{log, junit} {
+		if content, err := a.ReadContent(); err != nil || int64(len(content)) != a.Size {
+			t.Errorf("ReadContent() of %s = %q, %v, want %d bytes", a.FullName, content, err, a.Size)
+		}
+	}
+}
+
+func TestDetermineJobDetails(t *testing.T) {
+	// Serves the YAML of the Prow jobs with the IDs "ci-operator" and "no-target"
+	prow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		args := `["--target=e2e"]`
+		switch r.URL.Query().Get("prowjob") {
+		case "ci-operator":
+		case "no-target":
+			args = `["--report-credentials-file=/etc/report"]`
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "spec:\n  job: periodic-ci-e2e\n  pod_spec:\n    containers:\n    - args: %s\nstatus:\n  url: %s\n", args, OpenshiftCI.ProwJobURL(ciOperatorJobPrefix))
+	}))
+	defer prow.Close()
+	instance := OpenshiftCI
+	instance.ProwURL = prow.URL
+
+	tests := []struct {
+		name       string
+		cfg        ScannerConfig
+		wantTarget string
+		wantErr    bool
+	}{
+		{name: "target from the job's YAML", cfg: ScannerConfig{ProwJobID: "ci-operator"}, wantTarget: "e2e"},
+		{name: "target from the artifacts of a job without target in YAML", cfg: ScannerConfig{ProwJobID: "no-target"}, wantTarget: "e2e"},
+		{name: "target from the artifacts", cfg: ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix)}, wantTarget: "e2e"},
+		{
+			name:       "target from the rules",
+			cfg:        ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix), JobTargetRules: []JobTargetRule{{JobName: "^periodic-ci-", Target: "other"}}},
+			wantTarget: "other",
+		},
+		{name: "generic layout of a job not run by ci-operator", cfg: ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(genericJobPrefix)}},
+		{name: "generic layout forced", cfg: ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(ciOperatorJobPrefix), Layout: LayoutGeneric}},
+		{name: "ci-operator layout forced", cfg: ScannerConfig{ProwJobURL: OpenshiftCI.ProwJobURL(genericJobPrefix), Layout: LayoutCIOperator}, wantTarget: "tests"},
+		{name: "unknown job", cfg: ScannerConfig{ProwJobID: "unknown"}, wantErr: true},
+		{name: "no job", cfg: ScannerConfig{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.ArtifactSource = NewLocalSource(testBucket)
+			tt.cfg.Instance = instance
+			tt.cfg.Retry = RetryConfig{MaxAttempts: 1}
+			scanner, err := NewArtifactScanner(tt.cfg)
+			if err != nil {
+				t.Fatalf("failed to create the scanner: %+v", err)
+			}
+
+			target, pjURL, err := scanner.determineJobDetails(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("determineJobDetails() didn't fail, returned target %q", target)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("determineJobDetails failed: %+v", err)
+			}
+			if target != tt.wantTarget {
+				t.Errorf("target = %q, want %q", target, tt.wantTarget)
+			}
+			if !strings.HasPrefix(pjURL, OpenshiftCI.ProwURL+"/view/gs/") {
+				t.Errorf("unexpected Prow job URL %q", pjURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"k8s.io/klog/v2"
+)
+
+// RetryConfig limits retries of operations failing with transient
+// errors (HTTP 5xx and 429 responses, connection resets, timeouts)
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts (including the first one)
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, it's doubled after each retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper limit of the delay between retries
+	MaxBackoff time.Duration
+}
+
+// httpStatusError is returned by HTTP requests that got an unsuccessful response
+type httpStatusError struct {
+	url        string
+	statusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("got response status code %d from %s", e.statusCode, e.url)
+}
+
+// Helper function to call 'fn' until it succeeds, fails with an error that is not transient,
+// the context is done or the maximum number of attempts is reached. Delays between attempts
+// grow exponentially and are randomized to avoid retrying many operations at once
+func retry(ctx context.Context, cfg RetryConfig, operation string, fn func() error) error {
+	backoff := cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= cfg.MaxAttempts || !isTransientError(err) {
+			return err
+		}
+
+		// Randomize the delay within the upper half of the backoff
+		delay := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1)) // #nosec G404
+		klog.Warningf("%s failed (attempt %d/%d), retrying in %s: %+v", operation, attempt, cfg.MaxAttempts, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s failed: %+v (%+v)", operation, err, ctx.Err())
+		case <-time.After(delay):
+		}
+
+		if backoff *= 2; backoff > cfg.MaxBackoff {
+			backoff = cfg.MaxBackoff
+		}
+	}
+}
+
+// Helper function to check whether the given error is worth retrying
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return isTransientStatusCode(statusErr.statusCode)
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return isTransientStatusCode(apiErr.Code)
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isTransientStatusCode(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
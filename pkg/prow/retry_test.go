@@ -0,0 +1,93 @@
+package prow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "HTTP 503", err: &httpStatusError{statusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "HTTP 429", err: &httpStatusError{statusCode: http.StatusTooManyRequests}, want: true},
+		{name: "HTTP 404", err: &httpStatusError{statusCode: http.StatusNotFound}},
+		{name: "GCS 502", err: fmt.Errorf("failed to iterate over storage objects: %w", &googleapi.Error{Code: http.StatusBadGateway}), want: true},
+		{name: "GCS 403", err: &googleapi.Error{Code: http.StatusForbidden}},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: true},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true},
+		{name: "unexpected EOF", err: fmt.Errorf("cannot read from storage reader: %w", io.ErrUnexpectedEOF), want: true},
+		{name: "network timeout", err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, want: true},
+		{name: "canceled", err: fmt.Errorf("failed: %w", context.Canceled)},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "missing object", err: fmt.Errorf("failed to open x: %w", ErrObjectNotExist)},
+		{name: "other error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	transient := &httpStatusError{statusCode: http.StatusInternalServerError}
+	cfg := RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "success", errs: []error{nil}, wantAttempts: 1},
+		{name: "success after transient errors", errs: []error{transient, transient, nil}, wantAttempts: 3},
+		{name: "too many transient errors", errs: []error{transient, transient, transient, nil}, wantAttempts: 3, wantErr: transient},
+		{name: "permanent error", errs: []error{ErrObjectNotExist, nil}, wantAttempts: 1, wantErr: ErrObjectNotExist},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := retry(context.Background(), cfg, "test", func() error {
+				attempts++
+				return tt.errs[attempts-1]
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("retry() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := retry(ctx, RetryConfig{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}, "test", func() error {
+		attempts++
+		return &httpStatusError{statusCode: http.StatusBadGateway}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("retry() = %v after %d attempts, want an error after 1 attempt", err, attempts)
+	}
+}
@@ -0,0 +1,262 @@
+package prow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	v1 "k8s.io/test-infra/prow/apis/prowjobs/v1"
+)
+
+// ErrObjectNotExist is returned by ArtifactSource implementations
+// when the requested object doesn't exist
+var ErrObjectNotExist = errors.New("object doesn't exist")
+
+// ArtifactSource provides access to the objects (files) produced by Prow jobs.
+// Object names are always slash-separated paths relative to the root
+// of the source, e.g. "pr-logs/pull/<org>_<repo>/<pr>/<job>/<build-id>/build-log.txt"
+type ArtifactSource interface {
+	// List returns attributes of all objects whose name starts with the given prefix
+	List(ctx context.Context, prefix string) ([]ObjectAttrs, error)
+	// ListDirectories returns prefixes (ending with "/") of all "directories"
+	// directly within the given prefix, which is expected to end with "/"
+	ListDirectories(ctx context.Context, prefix string) ([]string, error)
+	// NewReader opens the object with the given name for reading
+	NewReader(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+// ObjectAttrs represents the attributes of an object stored in an ArtifactSource
+type ObjectAttrs struct {
+	Name string
+	Size int64
+	// Generation is the version of the object's content (only set for objects stored in GCS)
+	Generation int64
+}
+
+// GCSSource is an ArtifactSource backed by a Google Cloud Storage bucket
+type GCSSource struct {
+	bucketHandle *storage.BucketHandle
+}
+
+// NewGCSSource returns an ArtifactSource reading objects from the bucket with the given name
+func NewGCSSource(client *storage.Client, bucket string) *GCSSource {
+	return &GCSSource{bucketHandle: client.Bucket(bucket)}
+}
+
+// List returns attributes of all objects within the bucket whose name starts with the given prefix
+func (s *GCSSource) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+
+	it := s.bucketHandle.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", err)
+		}
+		objects = append(objects, ObjectAttrs{Name: attrs.Name, Size: attrs.Size, Generation: attrs.Generation})
+	}
+
+	return objects, nil
+}
+
+// ListDirectories returns prefixes of all "directories" directly within the given prefix
+func (s *GCSSource) ListDirectories(ctx context.Context, prefix string) ([]string, error) {
+	var dirs []string
+
+	it := s.bucketHandle.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over storage objects: %w", err)
+		}
+		// Only synthetic directory entries have the Prefix field set
+		if attrs.Prefix != "" {
+			dirs = append(dirs, attrs.Prefix)
+		}
+	}
+
+	return dirs, nil
+}
+
+// NewReader opens the object with the given name for reading
+func (s *GCSSource) NewReader(ctx context.Context, name string) (io.ReadCloser, error) {
+	rc, err := s.bucketHandle.Object(name).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("failed to create objecthandle for %s: %w", name, ErrObjectNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create objecthandle for %s: %w", name, err)
+	}
+	return rc, nil
+}
+
+// LocalSource is an ArtifactSource backed by a directory on a local disk.
+// The directory is expected to mirror the layout of the GCS bucket,
+// i.e. the object "logs/<job>/<build-id>/build-log.txt" is stored
+// in "<root>/logs/<job>/<build-id>/build-log.txt"
+type LocalSource struct {
+	root string
+	// mount is the prefix of the objects stored in the root directory (empty for the whole bucket)
+	mount string
+}
+
+// NewLocalSource returns an ArtifactSource reading files from the given root directory
+func NewLocalSource(root string) *LocalSource {
+	return &LocalSource{root: root}
+}
+
+// NewLocalJobSource returns an ArtifactSource reading files of a single Prow job from the given directory,
+// e.g. a directory created by "prowjob download". The directory is expected to mirror the layout of the job's
+// directory within the bucket, i.e. the object "<jobDirectoryPrefix>/artifacts/<step>/build-log.txt"
+// is stored in "<dir>/artifacts/<step>/build-log.txt"
+func NewLocalJobSource(dir, jobDirectoryPrefix string) *LocalSource {
+	s := &LocalSource{root: dir}
+	if prefix := strings.Trim(jobDirectoryPrefix, "/"); prefix != "" {
+		s.mount = prefix + "/"
+	}
+	return s
+}
+
+// LocalJobDirectoryPrefix returns the prefix of the job's directory within the bucket of the given Prow instance
+// for the job stored in the given local directory. The prefix is determined from the URL of the job found
+// in its prowjob.json. If the URL isn't available, "logs/<job name>/local" is returned, where the job name
+// is taken from prowjob.json, or the name of the directory if there is no prowjob.json
+func LocalJobDirectoryPrefix(dir string, instance Instance) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %+v", dir, err)
+	}
+	jobName := filepath.Base(abs)
+
+	data, err := os.ReadFile(filepath.Join(dir, prowJobFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read %s: %+v", prowJobFileName, err)
+	}
+	if err == nil {
+		pj := &v1.ProwJob{}
+		if err := json.Unmarshal(data, pj); err != nil {
+			return "", fmt.Errorf("cannot unmarshal %s: %+v", prowJobFileName, err)
+		}
+		if pj.Status.URL != "" {
+			return instance.JobDirectoryPrefix(pj.Status.URL)
+		}
+		if pj.Spec.Job != "" {
+			jobName = pj.Spec.Job
+		}
+	}
+	return "logs/" + jobName + "/local", nil
+}
+
+// Helper function to return the slash-separated path (relative to the root directory)
+// of the object with the given name, false if the object can't be stored within the root directory
+func (s *LocalSource) relativePath(name string) (string, bool) {
+	if !strings.HasPrefix(name, s.mount) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, s.mount), true
+}
+
+// List returns attributes of all regular files within the root directory whose
+// slash-separated path (relative to the root) starts with the given prefix
+func (s *LocalSource) List(ctx context.Context, prefix string) ([]ObjectAttrs, error) {
+	var objects []ObjectAttrs
+
+	// Start walking from the deepest directory that is fully covered by the prefix
+	walkRoot := s.root
+	if relativePrefix, ok := s.relativePath(prefix); ok {
+		if i := strings.LastIndex(relativePrefix, "/"); i >= 0 {
+			walkRoot = filepath.Join(s.root, filepath.FromSlash(relativePrefix[:i]))
+		}
+	} else if !strings.HasPrefix(s.mount, prefix) {
+		return nil, nil
+	}
+
+	err := filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		name := s.mount + filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectAttrs{Name: name, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory %s: %+v", walkRoot, err)
+	}
+
+	return objects, nil
+}
+
+// ListDirectories returns prefixes of all subdirectories of the directory
+// represented by the given prefix
+func (s *LocalSource) ListDirectories(_ context.Context, prefix string) ([]string, error) {
+	relativePrefix, ok := s.relativePath(prefix)
+	if !ok {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(filepath.Join(s.root, filepath.FromSlash(filepath.Clean("/"+relativePrefix))))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory %s: %+v", prefix, err)
+	}
+
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, prefix+e.Name()+"/")
+		}
+	}
+
+	return dirs, nil
+}
+
+// NewReader opens the file with the given name (relative to the root directory) for reading
+func (s *LocalSource) NewReader(_ context.Context, name string) (io.ReadCloser, error) {
+	relativeName, ok := s.relativePath(name)
+	if !ok {
+		return nil, fmt.Errorf("failed to open %s: %w", name, ErrObjectNotExist)
+	}
+	f, err := os.Open(filepath.Join(s.root, filepath.FromSlash(filepath.Clean("/"+relativeName))))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open %s: %w", name, ErrObjectNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %+v", name, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,129 @@
+package prow
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"k8s.io/klog/v2"
+)
+
+// Directories within the "artifacts/" directory that are created
+// by ci-operator itself and do not represent a job target
+var nonTargetArtifactDirectories = []string{"build-logs", "build-resources", "release"}
+
+// ciOperatorLogFileName is the name of the file stored within
+// the "artifacts/" directory of all jobs run by ci-operator
+const ciOperatorLogFileName = "ci-operator.log"
+
+// JobTargetRule maps Prow jobs to the ci-operator target, i.e. the name of the directory
+// (within the "artifacts/" directory) containing artifacts of the job's steps
+type JobTargetRule struct {
+	// JobName is a regular expression matched against the name of the Prow job
+	JobName string `json:"jobName"`
+	// URL is a regular expression matched against the Prow job URL
+	URL string `json:"url"`
+	// Target is the ci-operator target of matching Prow jobs
+	Target string `json:"target"`
+}
+
+// DefaultJobTargetRules map jobs of the repositories whose job targets can't be detected from the artifacts
+// reliably. They are applied after the rules from the ScannerConfig, so they can be overridden
+var DefaultJobTargetRules = []JobTargetRule{
+	{URL: "pull-ci-redhat-appstudio-infra-deployments", Target: "appstudio-e2e-tests"},
+	{URL: "pull-ci-redhat-appstudio-e2e-tests", Target: "redhat-appstudio-e2e"},
+	{URL: "pull-ci-redhat-appstudio-integration-service", Target: "integration-service-e2e"},
+}
+
+type jobTargetMatcher struct {
+	jobName *regexp.Regexp
+	url     *regexp.Regexp
+	target  string
+}
+
+func compileJobTargetRules(rules []JobTargetRule) ([]jobTargetMatcher, error) {
+	matchers := make([]jobTargetMatcher, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Target == "" {
+			return nil, fmt.Errorf("job target rule %+v doesn't specify the target", rule)
+		}
+		if rule.JobName == "" && rule.URL == "" {
+			return nil, fmt.Errorf("job target rule for target %q has to specify either a job name or URL pattern", rule.Target)
+		}
+
+		m := jobTargetMatcher{target: rule.Target}
+		var err error
+		if rule.JobName != "" {
+			if m.jobName, err = regexp.Compile(rule.JobName); err != nil {
+				return nil, fmt.Errorf("invalid job name pattern %q for target %q: %+v", rule.JobName, rule.Target, err)
+			}
+		}
+		if rule.URL != "" {
+			if m.url, err = regexp.Compile(rule.URL); err != nil {
+				return nil, fmt.Errorf("invalid URL pattern %q for target %q: %+v", rule.URL, rule.Target, err)
+			}
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Helper function to determine the job target using the ScannerConfig.JobTargetRules,
+// falling back to detecting the target from the content of the "artifacts/" directory.
+// Returns an empty target for jobs that were not run by ci-operator when using LayoutAuto
+func (as *ArtifactScanner) resolveJobTarget(ctx context.Context, jobName, prowJobURL string) (string, error) {
+	for _, m := range as.jobTargetMatchers {
+		if (m.jobName != nil && m.jobName.MatchString(jobName)) || (m.url != nil && m.url.MatchString(prowJobURL)) {
+			return m.target, nil
+		}
+	}
+
+	klog.Infof("none of the job target rules matches the job %q - detecting the target from its artifacts", jobName)
+	jobDirectoryPrefix, err := as.config.Instance.JobDirectoryPrefix(prowJobURL)
+	if err != nil {
+		return "", err
+	}
+
+	if as.config.Layout == LayoutAuto {
+		objects, err := as.list(ctx, jobDirectoryPrefix+"/artifacts/"+ciOperatorLogFileName)
+		if err != nil {
+			return "", fmt.Errorf("failed to list storage objects: %+v", err)
+		}
+		if len(objects) == 0 {
+			klog.Infof("the job %q wasn't run by ci-operator - using the generic artifact layout", jobName)
+			return "", nil
+		}
+	}
+
+	return as.detectJobTargetFromArtifacts(ctx, jobDirectoryPrefix)
+}
+
+// Helper function to detect the job target from subdirectories of the "artifacts/" directory
+func (as *ArtifactScanner) detectJobTargetFromArtifacts(ctx context.Context, jobDirectoryPrefix string) (string, error) {
+	dirs, err := as.listDirectories(ctx, jobDirectoryPrefix+"/artifacts/")
+	if err != nil {
+		return "", fmt.Errorf("failed to list artifact directories: %+v", err)
+	}
+
+	var candidates []string
+	for _, dir := range dirs {
+		name := path.Base(strings.TrimSuffix(dir, "/"))
+		if !slices.Contains(nonTargetArtifactDirectories, name) {
+			candidates = append(candidates, name)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return "", fmt.Errorf("unable to detect the target from artifact directories of the job %s: expected exactly one target directory, found %v", jobDirectoryPrefix, candidates)
+	}
+	return candidates[0], nil
+}
+
+// Helper function to get the name of the Prow job from the job's directory prefix
+// e.g. "logs/periodic-ci-redhat-appstudio-e2e-tests-main-periodic/123" => "periodic-ci-redhat-appstudio-e2e-tests-main-periodic"
+func getJobNameFromJobDirectoryPrefix(jobDirectoryPrefix string) string {
+	return path.Base(path.Dir(jobDirectoryPrefix))
+}
@@ -1,23 +1,56 @@
 package prow
 
 import (
+	"net/http"
+	"sync"
+	"time"
+
 	"cloud.google.com/go/storage"
 )
 
+// ReportStepName is the name of the openshift-ci step where the "createReport" command is used
+const ReportStepName = "redhat-appstudio-report"
+
+const (
+	defaultConcurrency     = 10
+	defaultObjectTimeout   = time.Minute
+	defaultMaxInMemorySize = 10 << 20 // 10 MiB
+	listTimeout            = time.Minute * 2
+	jobRunTimeout          = time.Minute
+	httpTimeout            = time.Minute
+	defaultMaxAttempts     = 5
+	defaultInitialBackoff  = time.Second
+	defaultMaxBackoff      = time.Second * 30
+)
+
+// ArtifactLayout describes how artifacts of a Prow job are organized
+type ArtifactLayout string
+
 const (
-	// The name of the openshift-ci step where the "createReport" command is used
-	reportStepName    = "redhat-appstudio-report"
-	bucketName        = "test-platform-results"
-	prowJobYAMLPrefix = "https://prow.ci.openshift.org/prowjob?prowjob="
+	// LayoutAuto uses the ci-operator layout for jobs run by ci-operator and the generic layout for other jobs
+	LayoutAuto ArtifactLayout = "auto"
+	// LayoutCIOperator expects artifacts of the job's steps in "artifacts/<target>/<step>/"
+	LayoutCIOperator ArtifactLayout = "ci-operator"
+	// LayoutGeneric treats the "artifacts/" directory as the root and its subdirectories as steps,
+	// files stored directly within the "artifacts/" directory belong to the step "/"
+	LayoutGeneric ArtifactLayout = "generic"
 )
 
 // ArtifactScanner is used for initializing
 // GCS client and scanning and storing
 // files found in defined storage
 type ArtifactScanner struct {
-	bucketHandle *storage.BucketHandle
-	Client       *storage.Client
-	config       ScannerConfig
+	// Client is only set when the artifacts are read from GCS
+	Client            *storage.Client
+	config            ScannerConfig
+	source            ArtifactSource
+	jobTargetMatchers []jobTargetMatcher
+	filter            artifactFilter
+	httpClient        *http.Client
+	// prowJobURL is the URL of the scanned job, determined by Run
+	prowJobURL string
+	// mu guards ArtifactStepMap and SkippedArtifacts while the artifacts are being downloaded
+	mu sync.Mutex
 	/* Example:
 	{
 	  "gather-extra": {"build-log.txt": {Content: "<content>", FullName: "/full/gcs/path/build-log.txt"}, "finished.json": ...},
@@ -26,15 +59,64 @@ type ArtifactScanner struct {
 	*/
 	ArtifactStepMap         map[ArtifactStepName]ArtifactFilenameMap
 	ArtifactDirectoryPrefix string
+	// SkippedArtifacts contains full names of the artifacts that couldn't be downloaded by Run,
+	// so the ArtifactStepMap is incomplete if there are any
+	SkippedArtifacts []string
 }
 
 // ScannerConfig contains fields required
 // for scaning files with ArtifactScanner
 type ScannerConfig struct {
+	// ArtifactSource overrides the storage the artifacts are read from (GCS bucket by default)
+	ArtifactSource ArtifactSource
+	// Instance is the Prow deployment the scanned job belongs to (defaults to OpenshiftCI)
+	Instance Instance
+	// FileNameFilter contains regular expressions (matched against full object names) of files that should be scanned
 	FileNameFilter []string
-	ProwJobID      string
-	ProwJobURL     string
-	StepsToSkip    []string
+	// FileNameExcludeFilter contains regular expressions (matched against full object names) of files that should be skipped
+	FileNameExcludeFilter []string
+	// IncludeRules select additional files that should be scanned, see filter.go for the syntax of the rules
+	IncludeRules []string
+	// ExcludeRules select files that should be skipped, see filter.go for the syntax of the rules
+	ExcludeRules []string
+	ProwJobID    string
+	ProwJobURL   string
+	// StepsToSkip contains names of openshift-ci steps whose files should be skipped
+	StepsToSkip []string
+	// JobTargetRules are used for determining the ci-operator target of jobs
+	// that are not scanned by their ProwJobID, before the DefaultJobTargetRules.
+	// If none of the rules matches, the target is detected from the job's "artifacts/" directory
+	JobTargetRules []JobTargetRule
+	// Layout of the job's artifacts (defaults to LayoutAuto)
+	Layout ArtifactLayout
+	// Concurrency limits the number of artifacts downloaded in parallel (defaults to 10)
+	Concurrency int
+	// ObjectTimeout limits the time spent on downloading a single artifact (defaults to 1 minute)
+	ObjectTimeout time.Duration
+	// SpoolDir is a directory where artifacts bigger than MaxInMemorySize are stored,
+	// instead of holding their content in memory. If empty, all artifacts are held in memory
+	SpoolDir string
+	// MaxInMemorySize is the size limit (in bytes) of artifacts held in memory
+	// when SpoolDir is set (defaults to 10 MiB)
+	MaxInMemorySize int64
+	// CacheDir is a directory for caching content of downloaded objects between scans.
+	// Caching is disabled if empty
+	CacheDir string
+	// CacheMaxSize is the size limit (in bytes) of the CacheDir - least recently used objects
+	// are evicted once the limit is exceeded. The size of the cache is not limited if not positive
+	CacheMaxSize int64
+	// Retry limits retries of requests failing with transient errors
+	// (defaults to 5 attempts with backoff growing from 1 second up to 30 seconds)
+	Retry RetryConfig
+}
+
+// requiredArtifact represents a storage object that should be
+// downloaded and stored in the ArtifactStepMap
+type requiredArtifact struct {
+	fileName string
+	fullName string
+	stepName string
+	size     int64
 }
 
 // ArtifactStepName represents the openshift-ci step name
@@ -46,17 +128,22 @@ type ArtifactFilenameMap map[ArtifactFilename]Artifact
 // ArtifactFilename represents the name of the file (including file extension)
 type ArtifactFilename string
 
-// Artifact stores the full name of the artifact (in GCS) and the content of the file
+// Artifact stores the full name of the artifact (in GCS) and the content of the file.
+// Artifacts spooled to disk (see ScannerConfig.SpoolDir) have an empty Content
+// and the Path pointing to the local copy of the file instead
 type Artifact struct {
 	Content  string
 	FullName string
+	Path     string
+	Size     int64
 }
 
 // OpenshiftJobSpec represents the Openshift job spec data
 type OpenshiftJobSpec struct {
-	Type string `json:"type"`
-	Job  string `json:"job"`
-	Refs Refs   `json:"refs"`
+	Type    string `json:"type"`
+	Job     string `json:"job"`
+	BuildID string `json:"buildid"`
+	Refs    Refs   `json:"refs"`
 }
 
 // Refs represent the refs field of an OpenShift job
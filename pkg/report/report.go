@@ -0,0 +1,138 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// SchemaVersion is the version of the report schema. It's increased on any change
+// that isn't backward compatible, i.e. renaming or removing fields or changing their meaning
+const SchemaVersion = 1
+
+// Statuses of steps and tests
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	// StatusPending is used for steps that haven't finished yet
+	StatusPending = "pending"
+)
+
+// Report is the machine-readable report of a Prow job (report.json)
+type Report struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Job           Job    `json:"job"`
+	Steps         []Step `json:"steps"`
+	Tests         []Test `json:"tests"`
+}
+
+// Job contains details of the Prow job run
+type Job struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Type is the type of the Prow job, e.g. "presubmit" or "periodic"
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url"`
+	// Result is the result of the job run, e.g. "SUCCESS", "FAILURE" or "PENDING"
+	Result          string     `json:"result"`
+	Started         *time.Time `json:"started,omitempty"`
+	Finished        *time.Time `json:"finished,omitempty"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	// Revision is the revision (commit SHA) the job run tested
+	Revision string `json:"revision,omitempty"`
+	Refs     *Refs  `json:"refs,omitempty"`
+}
+
+// Refs represent the git refs the job run tested
+type Refs struct {
+	Organization string `json:"org"`
+	Repo         string `json:"repo"`
+	RepoLink     string `json:"repoLink,omitempty"`
+	BaseRef      string `json:"baseRef,omitempty"`
+	BaseSHA      string `json:"baseSHA,omitempty"`
+	Pulls        []Pull `json:"pulls,omitempty"`
+}
+
+// Pull represents a pull request tested by the job run
+type Pull struct {
+	Number     int    `json:"number"`
+	Author     string `json:"author,omitempty"`
+	SHA        string `json:"sha,omitempty"`
+	Link       string `json:"link,omitempty"`
+	AuthorLink string `json:"authorLink,omitempty"`
+}
+
+// Step represents a step of the job run, e.g. an openshift-ci step
+type Step struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	// Result is the result of the step reported in its finished.json, e.g. "SUCCESS"
+	Result          string     `json:"result,omitempty"`
+	Started         *time.Time `json:"started,omitempty"`
+	Finished        *time.Time `json:"finished,omitempty"`
+	DurationSeconds float64    `json:"durationSeconds"`
+	ArtifactsURL    string     `json:"artifactsURL,omitempty"`
+	BuildLogURL     string     `json:"buildLogURL,omitempty"`
+}
+
+// Test represents a single test case of a JUnit report
+type Test struct {
+	Suite           string  `json:"suite"`
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	// FailureMessage is set for failed tests only
+	FailureMessage string `json:"failureMessage,omitempty"`
+}
+
+// TestsFromJUnit returns all test cases of the given JUnit suites, sorted by their suite and name
+func TestsFromJUnit(suites *reporters.JUnitTestSuites) []Test {
+	tests := []Test{}
+	for _, suite := range suites.TestSuites {
+		for _, tc := range suite.TestCases {
+			t := Test{Suite: suite.Name, Name: tc.Name, Status: StatusPassed, DurationSeconds: tc.Time}
+			switch {
+			case tc.Failure != nil:
+				t.Status, t.FailureMessage = StatusFailed, tc.Failure.Message
+			case tc.Error != nil:
+				t.Status, t.FailureMessage = StatusFailed, tc.Error.Message
+			case tc.Skipped != nil || tc.Status == "skipped" || tc.Status == "pending":
+				t.Status = StatusSkipped
+			}
+			tests = append(tests, t)
+		}
+	}
+
+	sort.SliceStable(tests, func(i, j int) bool {
+		if tests[i].Suite != tests[j].Suite {
+			return tests[i].Suite < tests[j].Suite
+		}
+		return tests[i].Name < tests[j].Name
+	})
+	return tests
+}
+
+// Decode parses the report, returning an error if its schema version isn't supported
+func Decode(data []byte) (*Report, error) {
+	r := &Report{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal report: %+v", err)
+	}
+	if r.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("unsupported report schema version %d, expected %d", r.SchemaVersion, SchemaVersion)
+	}
+	return r, nil
+}
+
+// TimePtr returns a pointer to the given time, or nil if the time is zero
+func TimePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -0,0 +1,211 @@
+package reportportal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	httpTimeout = time.Minute
+	// maxErrorBodySize limits the part of the response body included in errors
+	maxErrorBodySize = 1 << 10
+)
+
+// Types of test items
+const (
+	ItemTypeSuite = "SUITE"
+	ItemTypeTest  = "TEST"
+	ItemTypeStep  = "STEP"
+)
+
+// Statuses of test items
+const (
+	StatusPassed  = "PASSED"
+	StatusFailed  = "FAILED"
+	StatusSkipped = "SKIPPED"
+	StatusStopped = "STOPPED"
+)
+
+// Log levels
+const (
+	LogLevelInfo  = "info"
+	LogLevelError = "error"
+)
+
+// Client is a client of the Report Portal API (v1)
+type Client struct {
+	// BaseURL is the URL of the Report Portal instance, e.g. "https://reportportal.example.com"
+	BaseURL string
+	// Project is the name of the Report Portal project the launches are reported to
+	Project string
+	// Token is the API token used for authentication
+	Token string
+	// HTTPClient is used for sending requests (defaults to a client with 1 minute timeout)
+	HTTPClient *http.Client
+}
+
+// Attribute is a key-value attribute of a launch or a test item
+type Attribute struct {
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value"`
+}
+
+// StartLaunchRequest contains details of a launch to be started
+type StartLaunchRequest struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	StartTime   Timestamp   `json:"startTime"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+}
+
+// StartItemRequest contains details of a test item to be started
+type StartItemRequest struct {
+	LaunchID    string      `json:"launchUuid"`
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	StartTime   Timestamp   `json:"startTime"`
+	Attributes  []Attribute `json:"attributes,omitempty"`
+	// HasStats should be false for items whose parent's statistics shouldn't include the item
+	HasStats bool `json:"hasStats"`
+}
+
+// FinishItemRequest contains the result of a test item
+type FinishItemRequest struct {
+	LaunchID string    `json:"launchUuid"`
+	EndTime  Timestamp `json:"endTime"`
+	// Status is one of StatusPassed, StatusFailed, StatusSkipped or StatusStopped (computed from the item's children if empty)
+	Status string `json:"status,omitempty"`
+}
+
+// LogRequest contains a log message of a test item
+type LogRequest struct {
+	LaunchID string    `json:"launchUuid"`
+	ItemID   string    `json:"itemUuid,omitempty"`
+	Time     Timestamp `json:"time"`
+	Level    string    `json:"level"`
+	Message  string    `json:"message"`
+}
+
+// Timestamp is a time serialized as milliseconds since the Unix epoch, as expected by the Report Portal API
+type Timestamp time.Time
+
+// MarshalJSON encodes the timestamp as milliseconds since the Unix epoch
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Time(t).UnixMilli())
+}
+
+// UnmarshalJSON decodes the timestamp from milliseconds since the Unix epoch
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+	*t = Timestamp(time.UnixMilli(ms))
+	return nil
+}
+
+// entryCreatedResponse is the response to requests creating launches and test items
+type entryCreatedResponse struct {
+	ID string `json:"id"`
+}
+
+// StartLaunch starts a new launch and returns its ID (UUID)
+func (c *Client) StartLaunch(ctx context.Context, req StartLaunchRequest) (string, error) {
+	resp := &entryCreatedResponse{}
+	if err := c.do(ctx, http.MethodPost, "launch", req, resp); err != nil {
+		return "", fmt.Errorf("failed to start launch %q: %+v", req.Name, err)
+	}
+	return resp.ID, nil
+}
+
+// FinishLaunch finishes the launch with the given ID (UUID). The status is computed
+// from the launch's test items if empty, e.g. StatusStopped marks an interrupted launch
+func (c *Client) FinishLaunch(ctx context.Context, launchID string, endTime time.Time, status string) error {
+	req := struct {
+		EndTime Timestamp `json:"endTime"`
+		Status  string    `json:"status,omitempty"`
+	}{EndTime: Timestamp(endTime), Status: status}
+	if err := c.do(ctx, http.MethodPut, "launch/"+url.PathEscape(launchID)+"/finish", req, nil); err != nil {
+		return fmt.Errorf("failed to finish launch %s: %+v", launchID, err)
+	}
+	return nil
+}
+
+// StartItem starts a new test item within the item with the given parent ID (a root item of the launch
+// if the parent ID is empty) and returns its ID (UUID)
+func (c *Client) StartItem(ctx context.Context, parentID string, req StartItemRequest) (string, error) {
+	path := "item"
+	if parentID != "" {
+		path += "/" + url.PathEscape(parentID)
+	}
+	resp := &entryCreatedResponse{}
+	if err := c.do(ctx, http.MethodPost, path, req, resp); err != nil {
+		return "", fmt.Errorf("failed to start item %q: %+v", req.Name, err)
+	}
+	return resp.ID, nil
+}
+
+// FinishItem finishes the test item with the given ID
+func (c *Client) FinishItem(ctx context.Context, itemID string, req FinishItemRequest) error {
+	if err := c.do(ctx, http.MethodPut, "item/"+url.PathEscape(itemID), req, nil); err != nil {
+		return fmt.Errorf("failed to finish item %s: %+v", itemID, err)
+	}
+	return nil
+}
+
+// Log attaches a log message to the test item (or to the launch if the item ID is empty)
+func (c *Client) Log(ctx context.Context, req LogRequest) error {
+	if err := c.do(ctx, http.MethodPost, "log", req, nil); err != nil {
+		return fmt.Errorf("failed to save log of item %s: %+v", req.ItemID, err)
+	}
+	return nil
+}
+
+// Helper function to send the request with the given body (encoded as JSON) to the given path
+// within the project's API and decode the response into 'result' (unless it's nil)
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("cannot marshal request: %+v", err)
+	}
+
+	u := strings.TrimSuffix(c.BaseURL, "/") + "/api/v1/" + url.PathEscape(c.Project) + "/" + path
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("cannot create request %s %s: %+v", method, u, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: httpTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request %s %s failed: %+v", method, u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("request %s %s failed with status code %d: %s", method, u, resp.StatusCode, respBody)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("cannot decode response of %s %s: %+v", method, u, err)
+	}
+	return nil
+}
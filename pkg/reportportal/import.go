@@ -0,0 +1,160 @@
+package reportportal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+	"k8s.io/klog/v2"
+)
+
+// Launch contains details of the launch created by ImportJUnit
+type Launch struct {
+	Name        string
+	Description string
+	Attributes  []Attribute
+	// StartTime is the start time of the launch, test items start after each other
+	// according to their duration (defaults to the current time)
+	StartTime time.Time
+}
+
+// importer reports test items of a single launch
+type importer struct {
+	c        *Client
+	launchID string
+	// now is the end time of the last reported item
+	now time.Time
+	// open are IDs of the started items that haven't been finished yet, the innermost one is the last
+	open []string
+}
+
+// ImportJUnit reports the given JUnit suites as a new launch - each suite as a SUITE item and each of its test
+// cases as a STEP item. Failure messages and system-err of the test cases are attached to the items as error logs.
+// If reporting of the items fails, the unfinished items and the launch are finished as stopped, so the launch
+// doesn't stay in progress. Returns the ID of the created launch
+func ImportJUnit(ctx context.Context, c *Client, launch Launch, suites *reporters.JUnitTestSuites) (string, error) {
+	now := launch.StartTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	launchID, err := c.StartLaunch(ctx, StartLaunchRequest{
+		Name:        launch.Name,
+		Description: launch.Description,
+		StartTime:   Timestamp(now),
+		Attributes:  launch.Attributes,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	imp := &importer{c: c, launchID: launchID, now: now}
+	if err := imp.importSuites(ctx, suites); err != nil {
+		// A new context is used, as the failure may have been caused by canceling 'ctx'
+		stopCtx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+		defer cancel()
+		if stopErr := imp.stop(stopCtx); stopErr != nil {
+			return launchID, fmt.Errorf("%+v (%+v)", err, stopErr)
+		}
+		return launchID, err
+	}
+
+	return launchID, c.FinishLaunch(ctx, launchID, imp.now, "")
+}
+
+// Helper function to report the suites as items of the launch
+func (imp *importer) importSuites(ctx context.Context, suites *reporters.JUnitTestSuites) error {
+	for _, suite := range suites.TestSuites {
+		if len(suite.TestCases) == 0 {
+			continue
+		}
+
+		suiteID, err := imp.startItem(ctx, "", StartItemRequest{Name: suite.Name, Type: ItemTypeSuite})
+		if err != nil {
+			return err
+		}
+		for _, tc := range suite.TestCases {
+			if err := imp.importTestCase(ctx, suiteID, tc); err != nil {
+				return err
+			}
+		}
+		if err := imp.finishItem(ctx, suiteID, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *importer) importTestCase(ctx context.Context, suiteID string, tc reporters.JUnitTestCase) error {
+	itemID, err := imp.startItem(ctx, suiteID, StartItemRequest{Name: tc.Name, Type: ItemTypeStep, Description: tc.Classname})
+	if err != nil {
+		return err
+	}
+	imp.now = imp.now.Add(time.Duration(tc.Time * float64(time.Second)))
+
+	status := StatusPassed
+	var logs []string
+	switch {
+	case tc.Failure != nil:
+		status = StatusFailed
+		logs = append(logs, tc.Failure.Message)
+		if tc.Failure.Description != tc.Failure.Message {
+			logs = append(logs, tc.Failure.Description)
+		}
+	case tc.Error != nil:
+		status = StatusFailed
+		logs = append(logs, tc.Error.Message)
+		if tc.Error.Description != tc.Error.Message {
+			logs = append(logs, tc.Error.Description)
+		}
+	case tc.Skipped != nil || tc.Status == "skipped" || tc.Status == "pending" || tc.Status == "disabled":
+		status = StatusSkipped
+	}
+	if status == StatusFailed {
+		logs = append(logs, tc.SystemErr)
+	}
+
+	for _, message := range logs {
+		if message == "" {
+			continue
+		}
+		if err := imp.c.Log(ctx, LogRequest{LaunchID: imp.launchID, ItemID: itemID, Time: Timestamp(imp.now), Level: LogLevelError, Message: message}); err != nil {
+			return err
+		}
+	}
+
+	return imp.finishItem(ctx, itemID, status)
+}
+
+// Helper function to start an item at the current time and remember it as open
+func (imp *importer) startItem(ctx context.Context, parentID string, req StartItemRequest) (string, error) {
+	req.LaunchID, req.StartTime, req.HasStats = imp.launchID, Timestamp(imp.now), true
+	itemID, err := imp.c.StartItem(ctx, parentID, req)
+	if err != nil {
+		return "", err
+	}
+	imp.open = append(imp.open, itemID)
+	return itemID, nil
+}
+
+// Helper function to finish the innermost open item (with the given ID) at the current time
+func (imp *importer) finishItem(ctx context.Context, itemID, status string) error {
+	if err := imp.c.FinishItem(ctx, itemID, FinishItemRequest{LaunchID: imp.launchID, EndTime: Timestamp(imp.now), Status: status}); err != nil {
+		return err
+	}
+	imp.open = imp.open[:len(imp.open)-1]
+	return nil
+}
+
+// Helper function to finish all open items and the launch as stopped. Items that cannot
+// be finished are skipped, so that at least the launch is finished
+func (imp *importer) stop(ctx context.Context) error {
+	for i := len(imp.open) - 1; i >= 0; i-- {
+		if err := imp.c.FinishItem(ctx, imp.open[i], FinishItemRequest{LaunchID: imp.launchID, EndTime: Timestamp(imp.now), Status: StatusStopped}); err != nil {
+			klog.Warningf("cannot stop item %s of launch %s: %+v", imp.open[i], imp.launchID, err)
+		}
+	}
+	imp.open = nil
+	return imp.c.FinishLaunch(ctx, imp.launchID, imp.now, StatusStopped)
+}
@@ -0,0 +1,209 @@
+package reportportal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	reporters "github.com/onsi/ginkgo/v2/reporters"
+)
+
+// fakeServer records requests sent to the Report Portal API and responds to them with sequential UUIDs
+// (every successful request consumes one). Like Report Portal, it only accepts UUIDs of started launches
+// and items in paths, except for the stop endpoint, which takes the numeric ID of the launch.
+// Requests with a path listed in 'fail' fail with status code 500
+type fakeServer struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   []map[string]any
+	fail     map[string]bool
+	lastID   int
+	launches map[string]bool
+	items    map[string]bool
+}
+
+var (
+	launchPathRegexp = regexp.MustCompile(`^launch/([^/]+)/(finish|stop)$`)
+	itemPathRegexp   = regexp.MustCompile(`^item/([^/]+)$`)
+)
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/project/")
+	s.requests = append(s.requests, r.Method+" "+path)
+	body := map[string]any{}
+	data, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &body)
+	s.bodies = append(s.bodies, body)
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if m := launchPathRegexp.FindStringSubmatch(path); m != nil {
+		_, err := strconv.Atoi(m[1])
+		if known := m[2] == "finish" && s.launches[m[1]] || m[2] == "stop" && err == nil; !known {
+			http.Error(w, "launch "+m[1]+" not found", http.StatusNotFound)
+			return
+		}
+	}
+	if m := itemPathRegexp.FindStringSubmatch(path); m != nil && !s.items[m[1]] {
+		http.Error(w, "item "+m[1]+" not found", http.StatusNotFound)
+		return
+	}
+	if s.fail[path] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.lastID++
+	id := fmt.Sprintf("uuid-%d", s.lastID)
+	if s.launches == nil {
+		s.launches, s.items = map[string]bool{}, map[string]bool{}
+	}
+	switch {
+	case path == "launch":
+		s.launches[id] = true
+	case strings.HasPrefix(path, "item") && r.Method == http.MethodPost:
+		s.items[id] = true
+	}
+	fmt.Fprintf(w, `{"id": %q}`, id)
+}
+
+func testSuites() *reporters.JUnitTestSuites {
+	return &reporters.JUnitTestSuites{TestSuites: []reporters.JUnitTestSuite{
+		{Name: "empty"},
+		{Name: "e2e", TestCases: []reporters.JUnitTestCase{
+			{Name: "passes", Status: "passed", Time: 1},
+			{Name: "fails", Status: "failed", Time: 2, Failure: &reporters.JUnitFailure{Message: "expected true", Description: "expected true\nstack"}, SystemErr: "log"},
+		}},
+	}}
+}
+
+func TestImportJUnit(t *testing.T) {
+	server := &fakeServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	launch := Launch{Name: "job", Description: "https://prow/job", StartTime: start, Attributes: []Attribute{{Key: "repo", Value: "org/repo"}}}
+	launchID, err := ImportJUnit(context.Background(), &Client{BaseURL: ts.URL, Project: "project", Token: "token"}, launch, testSuites())
+	if err != nil {
+		t.Fatalf("ImportJUnit failed: %+v", err)
+	}
+	if launchID != "uuid-1" {
+		t.Errorf("launch ID = %q, want %q", launchID, "uuid-1")
+	}
+
+	want := []string{
+		"POST launch",
+		"POST item",
+		"POST item/uuid-2",
+		"PUT item/uuid-3",
+		"POST item/uuid-2",
+		"POST log",
+		"POST log",
+		"POST log",
+		"PUT item/uuid-5",
+		"PUT item/uuid-2",
+		"PUT launch/uuid-1/finish",
+	}
+	if !reflect.DeepEqual(server.requests, want) {
+		t.Fatalf("requests = %q, want %q", server.requests, want)
+	}
+
+	startLaunch := server.bodies[0]
+	if startLaunch["name"] != "job" || startLaunch["description"] != "https://prow/job" || startLaunch["startTime"] != float64(start.UnixMilli()) {
+		t.Errorf("unexpected start launch request: %+v", startLaunch)
+	}
+	if attributes := fmt.Sprint(startLaunch["attributes"]); attributes != "[map[key:repo value:org/repo]]" {
+		t.Errorf("launch attributes = %s", attributes)
+	}
+	if suite := server.bodies[1]; suite["name"] != "e2e" || suite["type"] != ItemTypeSuite {
+		t.Errorf("unexpected start suite request: %+v", suite)
+	}
+	for i, status := range map[int]string{3: StatusPassed, 8: StatusFailed} {
+		if server.bodies[i]["status"] != status {
+			t.Errorf("request %d (%s) status = %v, want %s", i, server.requests[i], server.bodies[i]["status"], status)
+		}
+	}
+	var messages []any
+	for _, body := range server.bodies[5:8] {
+		messages = append(messages, body["message"])
+	}
+	if want := []any{"expected true", "expected true\nstack", "log"}; !reflect.DeepEqual(messages, want) {
+		t.Errorf("log messages = %q, want %q", messages, want)
+	}
+	// Test cases follow each other according to their durations
+	if finish := server.bodies[10]; finish["endTime"] != float64(start.Add(3*time.Second).UnixMilli()) {
+		t.Errorf("launch end time = %v, want %v", finish["endTime"], start.Add(3*time.Second).UnixMilli())
+	}
+}
+
+func TestImportJUnitStopsLaunchOnError(t *testing.T) {
+	server := &fakeServer{fail: map[string]bool{"log": true}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	launchID, err := ImportJUnit(context.Background(), &Client{BaseURL: ts.URL, Project: "project", Token: "token"}, Launch{Name: "job"}, testSuites())
+	if err == nil {
+		t.Fatal("ImportJUnit didn't fail")
+	}
+	if launchID != "uuid-1" {
+		t.Errorf("launch ID = %q, want %q", launchID, "uuid-1")
+	}
+
+	// Logging the failure of the second test case fails, the test case, its suite and the launch are stopped
+	want := []string{
+		"POST launch",
+		"POST item",
+		"POST item/uuid-2",
+		"PUT item/uuid-3",
+		"POST item/uuid-2",
+		"POST log",
+		"PUT item/uuid-5",
+		"PUT item/uuid-2",
+		"PUT launch/uuid-1/finish",
+	}
+	if !reflect.DeepEqual(server.requests, want) {
+		t.Fatalf("requests = %q, want %q", server.requests, want)
+	}
+	for _, i := range []int{6, 7, 8} {
+		if status := server.bodies[i]["status"]; status != StatusStopped {
+			t.Errorf("request %d (%s) status = %v, want %s", i, server.requests[i], status, StatusStopped)
+		}
+	}
+}
+
+func TestFakeServerRejectsUnknownIdentifiers(t *testing.T) {
+	ts := httptest.NewServer(&fakeServer{})
+	defer ts.Close()
+	c := &Client{BaseURL: ts.URL, Project: "project", Token: "token"}
+
+	launchID, err := c.StartLaunch(context.Background(), StartLaunchRequest{Name: "job"})
+	if err != nil {
+		t.Fatalf("StartLaunch failed: %+v", err)
+	}
+	// The stop endpoint takes the numeric ID of the launch, not its UUID
+	if err := c.do(context.Background(), http.MethodPut, "launch/"+launchID+"/stop", struct{}{}, nil); err == nil {
+		t.Error("stopping the launch by its UUID didn't fail")
+	}
+	if err := c.FinishLaunch(context.Background(), "unknown", time.Now(), ""); err == nil {
+		t.Error("finishing an unknown launch didn't fail")
+	}
+	if err := c.FinishLaunch(context.Background(), launchID, time.Now(), ""); err != nil {
+		t.Errorf("FinishLaunch failed: %+v", err)
+	}
+}
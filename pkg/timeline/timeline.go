@@ -0,0 +1,114 @@
+package timeline
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/redhat-appstudio/qe-tools/pkg/prow"
+)
+
+// DefaultSlowShare is the default share of the job's duration above which a step is considered slow
+const DefaultSlowShare = 0.2
+
+// Step represents a single step on the timeline of a job run
+type Step struct {
+	Name     string    `json:"name"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	// DurationSeconds is zero for steps that haven't finished or whose start time is unknown
+	DurationSeconds float64 `json:"durationSeconds"`
+	Passed          bool    `json:"passed"`
+	// Slow is set for steps that took unusually long
+	Slow bool `json:"slow"`
+}
+
+// Timeline represents the steps of a job run in the order they started
+type Timeline struct {
+	JobName         string    `json:"jobName"`
+	ID              string    `json:"id"`
+	URL             string    `json:"url"`
+	Started         time.Time `json:"started"`
+	Finished        time.Time `json:"finished"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Steps           []Step    `json:"steps"`
+}
+
+// Options configure which steps are considered slow
+type Options struct {
+	// SlowShare is the share of the job's duration above which a step is considered slow (defaults to DefaultSlowShare)
+	SlowShare float64
+	// SlowThreshold is the duration above which a step is considered slow regardless of the job's duration (0 to disable)
+	SlowThreshold time.Duration
+}
+
+// New creates the timeline of the given job run from the start and finish times of its steps
+func New(jobRun *prow.JobRun, opts Options) Timeline {
+	if opts.SlowShare <= 0 {
+		opts.SlowShare = DefaultSlowShare
+	}
+
+	t := Timeline{JobName: jobRun.JobName, ID: jobRun.ID, URL: jobRun.URL, Started: jobRun.Started, Finished: jobRun.Finished, Steps: []Step{}}
+	for _, s := range jobRun.Steps {
+		if !s.Started.IsZero() && (t.Started.IsZero() || s.Started.Before(t.Started)) {
+			t.Started = s.Started
+		}
+		if s.Finished.After(t.Finished) {
+			t.Finished = s.Finished
+		}
+	}
+	if !t.Started.IsZero() && !t.Finished.IsZero() {
+		t.DurationSeconds = t.Finished.Sub(t.Started).Seconds()
+	}
+
+	for _, s := range jobRun.Steps {
+		d := s.Duration()
+		step := Step{Name: string(s.Name), Started: s.Started, Finished: s.Finished, DurationSeconds: d.Seconds(), Passed: s.Passed}
+		step.Slow = d > 0 && ((opts.SlowThreshold > 0 && d >= opts.SlowThreshold) ||
+			(t.DurationSeconds > 0 && d.Seconds() >= opts.SlowShare*t.DurationSeconds))
+		t.Steps = append(t.Steps, step)
+	}
+	return t
+}
+
+// HTML returns the timeline rendered as a Gantt chart, with failed steps in red and slow steps highlighted
+func (t Timeline) HTML() string {
+	var sb strings.Builder
+	sb.WriteString(`<div style="margin: 1em;"><h3>Timeline</h3>`)
+	if t.DurationSeconds <= 0 {
+		sb.WriteString("<p>Timeline is not available - start or finish time of the job is unknown</p></div>")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "<p>Started %s, took %s. Steps that took unusually long are highlighted.</p>",
+		t.Started.UTC().Format(time.RFC3339), formatSeconds(t.DurationSeconds))
+	sb.WriteString(`<table style="border-collapse: collapse; width: 100%;">`)
+	sb.WriteString(`<tr><th style="text-align: left;">Step</th><th style="text-align: right;">Duration</th><th style="width: 70%;"></th></tr>`)
+	for _, s := range t.Steps {
+		rowStyle := ""
+		if s.Slow {
+			rowStyle = ` style="background-color: #fff3cd; font-weight: bold;"`
+		}
+		color := "#5cb85c"
+		if !s.Passed {
+			color = "#d9534f"
+		}
+
+		bar := ""
+		if !s.Started.IsZero() && s.DurationSeconds > 0 {
+			left := s.Started.Sub(t.Started).Seconds() / t.DurationSeconds * 100
+			width := s.DurationSeconds / t.DurationSeconds * 100
+			bar = fmt.Sprintf(`<div title="%s - %s" style="margin-left: %.2f%%; width: %.2f%%; min-width: 2px; height: 1em; background-color: %s;"></div>`,
+				s.Started.UTC().Format(time.RFC3339), s.Finished.UTC().Format(time.RFC3339), left, width, color)
+		}
+		fmt.Fprintf(&sb, `<tr%s><td>%s</td><td style="text-align: right; padding-right: 1em;">%s</td><td>%s</td></tr>`,
+			rowStyle, html.EscapeString(s.Name), formatSeconds(s.DurationSeconds), bar)
+	}
+	sb.WriteString("</table></div>")
+	return sb.String()
+}
+
+func formatSeconds(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
@@ -5,6 +5,7 @@ const (
 	ArtifactDirEnv string = "ARTIFACT_DIR"
 	GithubTokenEnv string = "GITHUB_TOKEN" // #nosec G101
 	ProwJobIDEnv   string = "PROW_JOB_ID"
+	JobSpecEnv     string = "JOB_SPEC"
 
 	ArtifactDirParamName string = "artifact-dir"
 	ProwJobIDParamName   string = "prow-job-id"